@@ -0,0 +1,72 @@
+package serialise
+
+import "math/bits"
+
+// BitSet holds a sequence of bools as individual bits, using an eighth of the memory of a []bool.
+// From V4, MinData serialises a *BitSet directly from its bits, and deserialises
+// it without expanding the bits into a []bool.
+type BitSet struct {
+	words []uint64
+	n     int
+}
+
+// NewBitSet returns a BitSet holding n bools, all of which are false
+func NewBitSet(n int) *BitSet {
+	return &BitSet{words: make([]uint64, (n+63)/64), n: n}
+}
+
+// NewBitSetFromBools returns a BitSet holding the values of v
+func NewBitSetFromBools(v []bool) *BitSet {
+	b := NewBitSet(len(v))
+	for i, set := range v {
+		if set {
+			b.words[i/64] |= 1 << (i % 64)
+		}
+	}
+	return b
+}
+
+// Len returns the number of bools held by the BitSet
+func (b *BitSet) Len() int {
+	return b.n
+}
+
+// Get returns the value of the bool at index i, panicking if i is out of range
+func (b *BitSet) Get(i int) bool {
+	b.check(i)
+	return b.words[i/64]&(1<<(i%64)) != 0
+}
+
+// Set updates the value of the bool at index i, panicking if i is out of range
+func (b *BitSet) Set(i int, v bool) {
+	b.check(i)
+	if v {
+		b.words[i/64] |= 1 << (i % 64)
+	} else {
+		b.words[i/64] &^= 1 << (i % 64)
+	}
+}
+
+// Count returns the number of bools that are true
+func (b *BitSet) Count() int {
+	count := 0
+	for _, w := range b.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Bools returns the values of the BitSet as a []bool
+func (b *BitSet) Bools() []bool {
+	v := make([]bool, b.n)
+	for i := range v {
+		v[i] = b.words[i/64]&(1<<(i%64)) != 0
+	}
+	return v
+}
+
+func (b *BitSet) check(i int) {
+	if i < 0 || i >= b.n {
+		panic("BitSet index out of range")
+	}
+}
@@ -0,0 +1,45 @@
+package serialise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBitSet(t *testing.T) {
+
+	v := make([]bool, 130)
+	for i := range v {
+		v[i] = i%3 == 0
+	}
+
+	bs := NewBitSetFromBools(v)
+	if bs.Len() != len(v) {
+		t.Fatalf("Unexpected length: %d", bs.Len())
+	}
+	if bs.Count() != 44 {
+		t.Fatalf("Unexpected count: %d", bs.Count())
+	}
+	for i := range v {
+		if bs.Get(i) != v[i] {
+			t.Fatalf("(%d) Mismatch", i)
+		}
+	}
+	if !reflect.DeepEqual(bs.Bools(), v) {
+		t.Fatal("Unexpected mismatch from Bools()")
+	}
+
+	bs.Set(128, true)
+	bs.Set(0, false)
+	if !bs.Get(128) || bs.Get(0) || bs.Count() != 44 {
+		t.Fatal("Unexpected result of Set()")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected panic for index out of range")
+			}
+		}()
+		bs.Get(130)
+	}()
+}
@@ -0,0 +1,1138 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// CBORApproachName is the name of the CBOR Approach in the Approach registry
+const CBORApproachName = "CBOR"
+
+// CBOROptions adjust how CBOR serialisation is performed
+type CBOROptions struct {
+	// Deterministic applies the core deterministic encoding requirements of RFC 8949 section 4.2
+	Deterministic bool
+	// ExtendedTime serialises all times with fractional seconds as extended times (tag 1001, RFC 9581)
+	ExtendedTime bool
+	// GoTypes wraps values with their Go type (tag 27) if decoders would return a different type
+	GoTypes bool
+}
+
+// WithCBORDeterministic ensures that equal values are always serialised to identical bytes,
+// by sorting map keys and struct fields by their encoded bytes and encoding floats in
+// their shortest form, following the core deterministic encoding of RFC 8949
+func WithCBORDeterministic() func(*CBOROptions) {
+	return func(o *CBOROptions) {
+		o.Deterministic = true
+	}
+}
+
+// WithCBORExtendedTime serialises all time.Time values that have a fractional second as
+// extended times (tag 1001, RFC 9581) holding the seconds and nanoseconds since the epoch,
+// rather than as float seconds (tag 1) when float seconds hold the time exactly, so that
+// all such times have the same form.  Extended times are not understood by all CBOR decoders.
+func WithCBORExtendedTime() func(*CBOROptions) {
+	return func(o *CBOROptions) {
+		o.ExtendedTime = true
+	}
+}
+
+// WithCBORGoTypes wraps values as a serialised object (tag 27) holding the Go type and the
+// value, e.g. 27(["int8", 1]), if decoders would otherwise return a different type, so that
+// Unpack returns the same Go types that were serialised.  Tagged values are returned as tag
+// objects by decoders that are unaware of the Go types.
+func WithCBORGoTypes() func(*CBOROptions) {
+	return func(o *CBOROptions) {
+		o.GoTypes = true
+	}
+}
+
+// NewCBORApproach creates an instance of CBOR serialisation (RFC 8949), which serialises
+// the same types as MinData so that the output of Pack can be read by any CBOR decoder.
+//
+// Values use the standard CBOR major types wherever possible: integers, floats, bools, nil,
+// text strings, byte strings for []byte, arrays for slices and maps for maps and structs.
+// time.Time values are serialised as epoch-based dates (tag 1), with float seconds when the
+// time has a fractional second that float64 holds exactly, or otherwise as extended times
+// with nanoseconds (tag 1001, RFC 9581) so that times are not truncated, and numeric slices
+// are serialised as little endian typed arrays (RFC 8746).
+//
+// Unpack returns int64, uint64, float64, bool, string, []byte, time.Time, []any, map[string]any,
+// map[any]any and numeric slices for these items, so that, for example, int8 values are returned
+// as int64 and structs as maps.  WithCBORGoTypes wraps values of other types with their Go type
+// (tag 27), so that the same Go types are returned by Unpack.  Types are described in the same
+// way as the JSON Approach.  Within a tagged value, only values held by interface types are tagged.
+//
+// As ToBytes prefixes the compression flag, which is 0 when uncompressed, its output is a
+// CBOR sequence (RFC 8742) of the flag and the value when compression and encryption are disabled.
+//
+// Strings must be valid UTF-8 and time.Time values are deserialised in UTC.  Pointers are
+// serialised by value, so that shared pointers are deserialised as separate copies and
+// cyclic references are not supported.
+func NewCBORApproach(opts ...func(*CBOROptions)) Approach {
+	o := CBOROptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &cborApproach{deterministic: o.Deterministic, extendedTime: o.ExtendedTime, goTypes: o.GoTypes}
+}
+
+type cborApproach struct {
+	deterministic bool
+	extendedTime  bool
+	goTypes       bool
+}
+
+// ErrCBORTypeNotSerialisable is raised if a variable type is not serialisable by the CBOR approach
+var ErrCBORTypeNotSerialisable = errors.New("type of argument is not serialisable to CBOR")
+
+// ErrCBORInvalidUTF8 is raised if a string to be serialised by the CBOR approach is not valid UTF-8
+var ErrCBORInvalidUTF8 = errors.New("string is not valid UTF-8 and cannot be serialised to CBOR")
+
+// ErrCBORMaxNestingExceeded is raised if values are nested beyond the supported depth,
+// which will be the case if a cyclic reference is serialised by the CBOR approach
+var ErrCBORMaxNestingExceeded = errors.New("maximum nesting depth exceeded - cyclic references are not supported by CBOR")
+
+// ErrCBORInvalidData is raised if the data is not well-formed CBOR, or does not match the types described within it
+var ErrCBORInvalidData = errors.New("invalid data provided. data must be well-formed CBOR")
+
+// ErrCBORUnsupportedItem is raised if well-formed CBOR data contains an item that has no
+// equivalent Go value, such as an unknown tag, a simple value or a negative integer below math.MinInt64
+var ErrCBORUnsupportedItem = errors.New("CBOR data contains an item that cannot be deserialised")
+
+// CBOR major types, held in the top three bits of the initial byte of each item
+const (
+	cborMajorUnsigned byte = iota << 5
+	cborMajorNegative
+	cborMajorBytes
+	cborMajorText
+	cborMajorArray
+	cborMajorMap
+	cborMajorTag
+	cborMajorSimple
+)
+
+// Initial bytes of CBOR simple values and floats, and the break that ends indefinite length items
+const (
+	cborFalse     byte = 0xf4
+	cborTrue      byte = 0xf5
+	cborNull      byte = 0xf6
+	cborUndefined byte = 0xf7
+	cborFloat16   byte = 0xf9
+	cborFloat32   byte = 0xfa
+	cborFloat64   byte = 0xfb
+	cborBreak     byte = 0xff
+)
+
+// CBOR tags used by the CBOR approach
+const (
+	cborTagDateTimeString uint64 = 0     // RFC 3339 date/time string (RFC 8949)
+	cborTagEpochDateTime  uint64 = 1     // Seconds since the epoch (RFC 8949)
+	cborTagObject         uint64 = 27    // Serialised object: [typename, value] (IANA registry)
+	cborTagExtendedTime   uint64 = 1001  // Extended time: {1: seconds, -9: nanoseconds} (RFC 9581)
+	cborTagSelfDescribe   uint64 = 55799 // Self-described CBOR (RFC 8949)
+)
+
+// CBOR typed array tags (RFC 8746), of which those with multi-byte elements are little endian
+const (
+	cborTagUint8Array   uint64 = 64
+	cborTagUint16Array  uint64 = 69
+	cborTagUint32Array  uint64 = 70
+	cborTagUint64Array  uint64 = 71
+	cborTagInt8Array    uint64 = 72
+	cborTagInt16Array   uint64 = 77
+	cborTagInt32Array   uint64 = 78
+	cborTagInt64Array   uint64 = 79
+	cborTagFloat32Array uint64 = 85
+	cborTagFloat64Array uint64 = 86
+)
+
+// cborTypedArrayTags holds the typed array tag for slices of each numeric kind
+var cborTypedArrayTags = map[reflect.Kind]uint64{
+	reflect.Int8:    cborTagInt8Array,
+	reflect.Int16:   cborTagInt16Array,
+	reflect.Int32:   cborTagInt32Array,
+	reflect.Int64:   cborTagInt64Array,
+	reflect.Uint16:  cborTagUint16Array,
+	reflect.Uint32:  cborTagUint32Array,
+	reflect.Uint64:  cborTagUint64Array,
+	reflect.Float32: cborTagFloat32Array,
+	reflect.Float64: cborTagFloat64Array,
+}
+
+// cborTypedArrayTypes holds the slice type that is returned for each typed array tag
+var cborTypedArrayTypes = map[uint64]reflect.Type{
+	cborTagInt8Array:    reflect.TypeFor[[]int8](),
+	cborTagInt16Array:   reflect.TypeFor[[]int16](),
+	cborTagInt32Array:   reflect.TypeFor[[]int32](),
+	cborTagInt64Array:   reflect.TypeFor[[]int64](),
+	cborTagUint16Array:  reflect.TypeFor[[]uint16](),
+	cborTagUint32Array:  reflect.TypeFor[[]uint32](),
+	cborTagUint64Array:  reflect.TypeFor[[]uint64](),
+	cborTagFloat32Array: reflect.TypeFor[[]float32](),
+	cborTagFloat64Array: reflect.TypeFor[[]float64](),
+}
+
+// cborUntaggedTypes holds the types that are returned by decoders for untagged items
+var cborUntaggedTypes = map[reflect.Type]bool{
+	reflect.TypeFor[int64]():          true,
+	reflect.TypeFor[float64]():        true,
+	reflect.TypeFor[bool]():           true,
+	reflect.TypeFor[string]():         true,
+	reflect.TypeFor[[]any]():          true,
+	reflect.TypeFor[map[string]any](): true,
+	bytesType:                         true,
+	timeType:                          true,
+}
+
+// Name of the approach
+func (c *cborApproach) Name() string {
+	return CBORApproachName
+}
+
+// IsSerialisable returns true if an instance of the specified type
+// can be serialised
+func (c *cborApproach) IsSerialisable(v any) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	_, err := c.Pack(v)
+	return err == nil
+}
+
+// Pack serialises the instance to a byte slice
+func (c *cborApproach) Pack(data any) ([]byte, error) {
+	return c.AppendPack(nil, data)
+}
+
+// AppendPack appends the serialised instance to dst, returning the extended slice
+func (c *cborApproach) AppendPack(dst []byte, data any) ([]byte, error) {
+	if data == nil {
+		return append(dst, cborNull), nil
+	}
+	return c.appendTaggedCBOR(dst, reflect.ValueOf(data), 0)
+}
+
+// Unpack deserialises an instance from the byte slice
+func (c *cborApproach) Unpack(data []byte) (output any, e error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = nil
+			e = ErrCBORInvalidData
+		}
+	}()
+
+	d := cborDecoder{data: data}
+	x, err := d.item(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, ErrCBORInvalidData
+	}
+
+	v, err := readTaggedCBOR(x, 0)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// isMarshalerCBOR returns the TypeID of the type if it is a registered encoding.BinaryMarshaler or encoding.TextMarshaler
+func isMarshalerCBOR(t reflect.Type) (TypeID, bool) {
+	if id, ok := marshalerTypeIDMD(t); ok {
+		if _, ok := registeredName(t); ok {
+			return id, true
+		}
+	}
+	return UnknownType, false
+}
+
+// typedArrayTagCBOR returns the typed array tag for slices of the element type, if available
+func typedArrayTagCBOR(elem reflect.Type) (uint64, bool) {
+	if _, ok := codecForType(elem); ok {
+		return 0, false
+	}
+	if _, ok := isMarshalerCBOR(elem); ok {
+		return 0, false
+	}
+	tag, ok := cborTypedArrayTags[elem.Kind()]
+	return tag, ok
+}
+
+// appendTaggedCBOR appends the value, wrapping the value with its type (tag 27) when Go types
+// are selected and its untagged item would be decoded differently.  The type must be one that
+// can be described, so that the same types are serialisable whether or not Go types are selected.
+func (c *cborApproach) appendTaggedCBOR(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	t := v.Type()
+
+	untagged := cborUntaggedTypes[t]
+	if t.Kind() == reflect.Slice {
+		if tag, ok := cborTypedArrayTags[t.Elem().Kind()]; ok && cborTypedArrayTypes[tag] == t {
+			untagged = true
+		}
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map:
+		untagged = untagged && !v.IsNil() // nil would be decoded as a nil interface
+	}
+	if untagged {
+		return c.appendValueCBOR(b, v, depth)
+	}
+
+	name, ok := goTypeName(t)
+	if !ok {
+		return nil, ErrCBORTypeNotSerialisable
+	}
+	if !c.goTypes {
+		return c.appendValueCBOR(b, v, depth)
+	}
+
+	b = appendHeadCBOR(b, cborMajorTag, cborTagObject)
+	b = appendHeadCBOR(b, cborMajorArray, 2)
+	b = appendTextCBOR(b, name)
+	return c.appendValueCBOR(b, v, depth)
+}
+
+// appendValueCBOR appends the value, which is described by the type of v
+func (c *cborApproach) appendValueCBOR(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	if depth > maxNestingMD {
+		return nil, ErrCBORMaxNestingExceeded
+	}
+	depth++
+
+	t := v.Type()
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return append(b, cborNull), nil
+		}
+	}
+
+	switch t {
+	case timeType:
+		return c.appendTimeCBOR(b, v.Interface().(time.Time)), nil
+	case bitSetType:
+		bs := v.Interface().(BitSet)
+		return c.appendValueCBOR(b, reflect.ValueOf(bs.Bools()), depth)
+	}
+
+	if codec, ok := codecForType(t); ok {
+		cb, err := codec.pack(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return append(appendHeadCBOR(b, cborMajorBytes, uint64(len(cb))), cb...), nil
+	}
+
+	if _, ok := isMarshalerCBOR(t); ok {
+		return appendMarshalerCBOR(b, v.Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, cborTrue), nil
+		}
+		return append(b, cborFalse), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendIntCBOR(b, v.Int()), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendHeadCBOR(b, cborMajorUnsigned, v.Uint()), nil
+	case reflect.Float32:
+		return c.appendFloatCBOR(b, v.Float(), 32), nil
+	case reflect.Float64:
+		return c.appendFloatCBOR(b, v.Float(), 64), nil
+	case reflect.String:
+		if !utf8.ValidString(v.String()) {
+			return nil, ErrCBORInvalidUTF8
+		}
+		return appendTextCBOR(b, v.String()), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return append(appendHeadCBOR(b, cborMajorBytes, uint64(v.Len())), v.Bytes()...), nil
+		}
+		if tag, ok := typedArrayTagCBOR(t.Elem()); ok {
+			return appendTypedArrayCBOR(b, tag, v), nil
+		}
+		return c.appendSliceCBOR(b, v, depth)
+	case reflect.Map:
+		return c.appendMapCBOR(b, v, depth)
+	case reflect.Struct:
+		return c.appendStructCBOR(b, v, depth)
+	case reflect.Pointer:
+		return c.appendValueCBOR(b, v.Elem(), depth)
+	case reflect.Interface:
+		return c.appendTaggedCBOR(b, v.Elem(), depth)
+	default:
+		return nil, ErrCBORTypeNotSerialisable
+	}
+}
+
+// appendHeadCBOR appends the initial byte of an item of the major type, with its argument
+// encoded in the shortest form
+func appendHeadCBOR(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+// appendIntCBOR appends the integer as an unsigned or negative integer
+func appendIntCBOR(b []byte, i int64) []byte {
+	if i >= 0 {
+		return appendHeadCBOR(b, cborMajorUnsigned, uint64(i))
+	}
+	return appendHeadCBOR(b, cborMajorNegative, uint64(-1-i))
+}
+
+// appendTextCBOR appends the string, which must be valid UTF-8, as a text string
+func appendTextCBOR(b []byte, s string) []byte {
+	return append(appendHeadCBOR(b, cborMajorText, uint64(len(s))), s...)
+}
+
+// appendFloatCBOR appends the float with the precision of its type, or in the shortest
+// form that preserves its value when deterministic
+func (c *cborApproach) appendFloatCBOR(b []byte, f float64, bitSize int) []byte {
+	if c.deterministic {
+		if h, ok := float16BitsCBOR(f); ok {
+			return binary.BigEndian.AppendUint16(append(b, cborFloat16), h)
+		}
+		if float64(float32(f)) == f {
+			bitSize = 32
+		}
+	}
+	if bitSize == 32 {
+		return binary.BigEndian.AppendUint32(append(b, cborFloat32), math.Float32bits(float32(f)))
+	}
+	return binary.BigEndian.AppendUint64(append(b, cborFloat64), math.Float64bits(f))
+}
+
+// float16BitsCBOR returns the IEEE 754 half precision encoding of the float,
+// if the float can be represented exactly.  NaN is returned as the quiet NaN 0x7e00.
+func float16BitsCBOR(f float64) (uint16, bool) {
+	if math.IsNaN(f) {
+		return 0x7e00, true
+	}
+	if float64(float32(f)) != f {
+		return 0, false
+	}
+
+	bits := math.Float32bits(float32(f))
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		return sign | 0x7c00, true // Infinity, as NaN has been handled
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0:
+		return 0, false // float32 subnormals are below the range of float16
+	}
+
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15:
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		// float16 subnormals hold the significand shifted to an exponent of -24
+		full := mant | 0x800000
+		shift := uint(-(e + 1))
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+		return sign | uint16(full>>shift), true
+	}
+	return 0, false
+}
+
+// float16ValueCBOR returns the value of an IEEE 754 half precision float
+func float16ValueCBOR(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant != 0 {
+			return math.NaN()
+		}
+		f = math.Inf(1)
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// appendTimeCBOR appends the time as seconds since the epoch (tag 1), which are float seconds
+// if it has a fractional second that the float holds exactly.  Otherwise, or if extended times
+// are selected, a time with a fractional second is appended as an extended time (tag 1001)
+// holding the seconds and nanoseconds, so that the time is never truncated.
+func (c *cborApproach) appendTimeCBOR(b []byte, tm time.Time) []byte {
+	if tm.Nanosecond() == 0 {
+		return appendIntCBOR(appendHeadCBOR(b, cborMajorTag, cborTagEpochDateTime), tm.Unix())
+	}
+	if !c.extendedTime {
+		f := float64(tm.Unix()) + float64(tm.Nanosecond())/float64(time.Second)
+		if epochFloatTimeCBOR(f).Equal(tm) {
+			return c.appendFloatCBOR(appendHeadCBOR(b, cborMajorTag, cborTagEpochDateTime), f, 64)
+		}
+	}
+
+	b = appendHeadCBOR(b, cborMajorTag, cborTagExtendedTime)
+	b = appendHeadCBOR(b, cborMajorMap, 2)
+	b = appendIntCBOR(appendIntCBOR(b, 1), tm.Unix())
+	return appendIntCBOR(appendIntCBOR(b, -9), int64(tm.Nanosecond()))
+}
+
+// appendTypedArrayCBOR appends the numeric slice as a little endian typed array
+func appendTypedArrayCBOR(b []byte, tag uint64, v reflect.Value) []byte {
+	size := int(v.Type().Elem().Size())
+
+	b = appendHeadCBOR(b, cborMajorTag, tag)
+	b = appendHeadCBOR(b, cborMajorBytes, uint64(v.Len()*size))
+	for i := range v.Len() {
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.Int8:
+			b = append(b, byte(e.Int()))
+		case reflect.Int16:
+			b = binary.LittleEndian.AppendUint16(b, uint16(e.Int()))
+		case reflect.Int32:
+			b = binary.LittleEndian.AppendUint32(b, uint32(e.Int()))
+		case reflect.Int64:
+			b = binary.LittleEndian.AppendUint64(b, uint64(e.Int()))
+		case reflect.Uint16:
+			b = binary.LittleEndian.AppendUint16(b, uint16(e.Uint()))
+		case reflect.Uint32:
+			b = binary.LittleEndian.AppendUint32(b, uint32(e.Uint()))
+		case reflect.Uint64:
+			b = binary.LittleEndian.AppendUint64(b, e.Uint())
+		case reflect.Float32:
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(e.Float())))
+		case reflect.Float64:
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(e.Float()))
+		}
+	}
+	return b
+}
+
+// appendSliceCBOR appends the elements of the slice as an array
+func (c *cborApproach) appendSliceCBOR(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	var err error
+	b = appendHeadCBOR(b, cborMajorArray, uint64(v.Len()))
+	for i := range v.Len() {
+		if b, err = c.appendValueCBOR(b, v.Index(i), depth); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// cborEntry is an entry of a map, with its key already encoded
+type cborEntry struct {
+	key   []byte
+	value reflect.Value
+}
+
+// appendEntriesCBOR appends the entries as a map, sorted by their encoded keys when deterministic
+func (c *cborApproach) appendEntriesCBOR(b []byte, entries []cborEntry, depth int) ([]byte, error) {
+	if c.deterministic {
+		slices.SortFunc(entries, func(a, b cborEntry) int {
+			return bytes.Compare(a.key, b.key)
+		})
+	}
+
+	var err error
+	b = appendHeadCBOR(b, cborMajorMap, uint64(len(entries)))
+	for _, e := range entries {
+		if b, err = c.appendValueCBOR(append(b, e.key...), e.value, depth); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendMapCBOR appends the map, with keys of any type
+func (c *cborApproach) appendMapCBOR(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	entries := make([]cborEntry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := c.appendValueCBOR(nil, iter.Key(), depth)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, cborEntry{key: key, value: iter.Value()})
+	}
+	return c.appendEntriesCBOR(b, entries, depth)
+}
+
+// appendStructCBOR appends the exported fields of a registered struct as a map keyed by field name
+func (c *cborApproach) appendStructCBOR(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	entries := make([]cborEntry, 0, v.NumField())
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		entries = append(entries, cborEntry{key: appendTextCBOR(nil, f.Name), value: v.Field(i)})
+	}
+	return c.appendEntriesCBOR(b, entries, depth)
+}
+
+// appendMarshalerCBOR appends the output of a registered encoding.BinaryMarshaler
+// as a byte string, or of a registered encoding.TextMarshaler as a text string
+func appendMarshalerCBOR(b []byte, data any) ([]byte, error) {
+	switch v := data.(type) {
+	case encoding.BinaryMarshaler:
+		mb, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return append(appendHeadCBOR(b, cborMajorBytes, uint64(len(mb))), mb...), nil
+	case encoding.TextMarshaler:
+		mb, err := v.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(mb) {
+			return nil, ErrCBORInvalidUTF8
+		}
+		return append(appendHeadCBOR(b, cborMajorText, uint64(len(mb))), mb...), nil
+	default:
+		return nil, ErrCBORTypeNotSerialisable
+	}
+}
+
+// cborTag is a decoded tag and its content
+type cborTag struct {
+	num     uint64
+	content any
+}
+
+// cborPair is a decoded entry of a map
+type cborPair struct {
+	key, value any
+}
+
+// cborMap is a decoded map, with its entries in the order that they were encoded
+type cborMap []cborPair
+
+// cborDecoder decodes CBOR items into uint64 and int64 integers, float64, bool, nil, string,
+// []byte, []any, cborMap and cborTag, which are then converted to Go types by readValueCBOR
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+// head returns the major type and argument of the next item, and whether the item has an indefinite length
+func (d *cborDecoder) head() (major byte, arg uint64, indefinite bool, e error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, false, ErrCBORInvalidData
+	}
+	ib := d.data[d.pos]
+	d.pos++
+
+	major, ai := ib&0xe0, ib&0x1f
+
+	size := 0
+	switch {
+	case ai < 24:
+		return major, uint64(ai), false, nil
+	case ai <= 27:
+		size = 1 << (ai - 24)
+	case ai == 31:
+		return major, 0, true, nil
+	default:
+		return 0, 0, false, ErrCBORInvalidData
+	}
+
+	if len(d.data)-d.pos < size {
+		return 0, 0, false, ErrCBORInvalidData
+	}
+	for _, c := range d.data[d.pos : d.pos+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.pos += size
+	return major, arg, false, nil
+}
+
+// isBreak consumes the break that ends an indefinite length item, if it is next
+func (d *cborDecoder) isBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// item decodes the next item
+func (d *cborDecoder) item(depth int) (any, error) {
+	if depth > maxNestingMD {
+		return nil, ErrCBORMaxNestingExceeded
+	}
+	depth++
+
+	start := d.pos
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major < cborMajorBytes || major == cborMajorTag) {
+		return nil, ErrCBORInvalidData
+	}
+
+	remaining := uint64(len(d.data) - d.pos)
+
+	switch major {
+	case cborMajorUnsigned:
+		return arg, nil
+	case cborMajorNegative:
+		if arg > math.MaxInt64 {
+			return nil, ErrCBORUnsupportedItem
+		}
+		return -1 - int64(arg), nil
+	case cborMajorBytes, cborMajorText:
+		var s []byte
+		if indefinite {
+			// Indefinite length strings are a sequence of definite length strings of the same type
+			s = []byte{}
+			for !d.isBreak() {
+				if d.pos >= len(d.data) || d.data[d.pos]&0xe0 != major || d.data[d.pos]&0x1f == 31 {
+					return nil, ErrCBORInvalidData
+				}
+				x, err := d.item(depth)
+				if err != nil {
+					return nil, err
+				}
+				if major == cborMajorText {
+					s = append(s, x.(string)...)
+				} else {
+					s = append(s, x.([]byte)...)
+				}
+			}
+		} else {
+			if arg > remaining {
+				return nil, ErrCBORInvalidData
+			}
+			s = slices.Clone(d.data[d.pos : d.pos+int(arg)])
+			if s == nil {
+				s = []byte{}
+			}
+			d.pos += int(arg)
+		}
+		if major == cborMajorBytes {
+			return s, nil
+		}
+		if !utf8.Valid(s) {
+			return nil, ErrCBORInvalidData
+		}
+		return string(s), nil
+	case cborMajorArray:
+		if !indefinite && arg > remaining {
+			return nil, ErrCBORInvalidData // Each item requires at least one byte
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			x, err := d.item(depth)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, x)
+		}
+		return items, nil
+	case cborMajorMap:
+		if !indefinite && arg > remaining/2 {
+			return nil, ErrCBORInvalidData
+		}
+		m := make(cborMap, 0, arg)
+		for i := uint64(0); indefinite || i < arg; i++ {
+			if indefinite && d.isBreak() {
+				break
+			}
+			k, err := d.item(depth)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.item(depth)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, cborPair{key: k, value: v})
+		}
+		return m, nil
+	case cborMajorTag:
+		content, err := d.item(depth)
+		if err != nil {
+			return nil, err
+		}
+		return cborTag{num: arg, content: content}, nil
+	default:
+		switch d.data[start] {
+		case cborFalse:
+			return false, nil
+		case cborTrue:
+			return true, nil
+		case cborNull, cborUndefined:
+			return nil, nil
+		case cborFloat16:
+			return float16ValueCBOR(uint16(arg)), nil
+		case cborFloat32:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		case cborFloat64:
+			return math.Float64frombits(arg), nil
+		case cborBreak:
+			return nil, ErrCBORInvalidData
+		}
+		return nil, ErrCBORUnsupportedItem
+	}
+}
+
+// readTaggedCBOR returns the value of a decoded item of any type, using the type
+// within a serialised object (tag 27) or the default type of the item
+func readTaggedCBOR(x any, depth int) (reflect.Value, error) {
+	switch x := x.(type) {
+	case nil:
+		return reflect.New(anyType).Elem(), nil
+	case uint64:
+		if x <= math.MaxInt64 {
+			return reflect.ValueOf(int64(x)), nil
+		}
+		return reflect.ValueOf(x), nil
+	case int64, float64, bool, string, []byte:
+		return reflect.ValueOf(x), nil
+	case []any:
+		return readValueCBOR(reflect.TypeFor[[]any](), x, depth)
+	case cborMap:
+		t := reflect.TypeFor[map[string]any]()
+		for _, p := range x {
+			if _, ok := p.key.(string); !ok {
+				t = reflect.TypeFor[map[any]any]()
+				break
+			}
+		}
+		return readValueCBOR(t, x, depth)
+	case cborTag:
+		switch x.num {
+		case cborTagDateTimeString, cborTagEpochDateTime, cborTagExtendedTime:
+			return readValueCBOR(timeType, x, depth)
+		case cborTagUint8Array:
+			return reflect.ValueOf(x.content.([]byte)), nil
+		case cborTagSelfDescribe:
+			return readTaggedCBOR(x.content, depth)
+		case cborTagObject:
+			obj := x.content.([]any)
+			if len(obj) != 2 {
+				return reflect.Value{}, ErrCBORInvalidData
+			}
+			t, err := parseGoTypeName(obj[0].(string))
+			if errors.Is(err, errInvalidTypeName) {
+				return reflect.Value{}, ErrCBORInvalidData
+			}
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return readValueCBOR(t, obj[1], depth)
+		}
+		if t, ok := cborTypedArrayTypes[x.num]; ok {
+			return readValueCBOR(t, x, depth)
+		}
+	}
+	return reflect.Value{}, ErrCBORUnsupportedItem
+}
+
+// intCBOR returns the value of a decoded integer as an int64
+func intCBOR(x any) (int64, bool) {
+	switch i := x.(type) {
+	case uint64:
+		return int64(i), i <= math.MaxInt64
+	case int64:
+		return i, true
+	}
+	return 0, false
+}
+
+// readValueCBOR returns the value of type t, from the decoded item created by appendValueCBOR
+func readValueCBOR(t reflect.Type, x any, depth int) (reflect.Value, error) {
+	if depth > maxNestingMD {
+		return reflect.Value{}, ErrCBORMaxNestingExceeded
+	}
+	depth++
+
+	v := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if x == nil {
+			return v, nil
+		}
+	}
+
+	switch t {
+	case timeType:
+		tm, err := readTimeCBOR(x.(cborTag))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return v, nil
+	case bitSetType:
+		bools, err := readValueCBOR(reflect.TypeFor[[]bool](), x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(*NewBitSetFromBools(bools.Interface().([]bool))))
+		return v, nil
+	}
+
+	if codec, ok := codecForType(t); ok {
+		cv, err := codec.unpack(x.([]byte))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(cv))
+		return v, nil
+	}
+
+	if id, ok := isMarshalerCBOR(t); ok {
+		return readMarshalerCBOR(t, id, x)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(x.(bool))
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := intCBOR(x)
+		if !ok || v.OverflowInt(i) {
+			return reflect.Value{}, ErrCBORInvalidData
+		}
+		v.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, ok := x.(uint64)
+		if !ok || v.OverflowUint(u) {
+			return reflect.Value{}, ErrCBORInvalidData
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f := x.(float64)
+		if !math.IsInf(f, 0) && v.OverflowFloat(f) {
+			return reflect.Value{}, ErrCBORInvalidData
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(x.(string))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(x.([]byte))
+			return v, nil
+		}
+		if tag, ok := typedArrayTagCBOR(t.Elem()); ok {
+			if tg, ok := x.(cborTag); ok {
+				if tg.num != tag {
+					return reflect.Value{}, ErrCBORInvalidData
+				}
+				return readTypedArrayCBOR(t, tg.content.([]byte))
+			}
+		}
+		items := x.([]any)
+		v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		for i, item := range items {
+			iv, err := readValueCBOR(t.Elem(), item, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(iv)
+		}
+	case reflect.Map:
+		m := x.(cborMap)
+		v.Set(reflect.MakeMapWithSize(t, len(m)))
+		for _, p := range m {
+			kv, err := readValueCBOR(t.Key(), p.key, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ev, err := readValueCBOR(t.Elem(), p.value, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.SetMapIndex(kv, ev)
+		}
+	case reflect.Struct:
+		for _, p := range x.(cborMap) {
+			f := v.FieldByName(p.key.(string))
+			if !f.IsValid() || !f.CanSet() {
+				continue // Fields that are no longer present within the struct type are ignored
+			}
+			fv, err := readValueCBOR(f.Type(), p.value, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			f.Set(fv)
+		}
+	case reflect.Pointer:
+		ev, err := readValueCBOR(t.Elem(), x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(ev)
+		v.Set(p)
+	case reflect.Interface:
+		iv, err := readTaggedCBOR(x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !iv.Type().AssignableTo(t) {
+			return reflect.Value{}, ErrCBORInvalidData
+		}
+		v.Set(iv)
+	default:
+		return reflect.Value{}, ErrCBORInvalidData
+	}
+	return v, nil
+}
+
+// epochFloatTimeCBOR returns the time of the float seconds since the epoch, rounded to the nearest nanosecond
+func epochFloatTimeCBOR(f float64) time.Time {
+	sec := math.Floor(f)
+	return time.Unix(int64(sec), int64(math.Round((f-sec)*float64(time.Second)))).UTC()
+}
+
+// readTimeCBOR returns the time of a date/time string (tag 0), epoch-based date/time (tag 1)
+// or extended time (tag 1001).  Times other than date/time strings are returned in UTC.
+func readTimeCBOR(tg cborTag) (time.Time, error) {
+	switch tg.num {
+	case cborTagDateTimeString:
+		tm, err := time.Parse(time.RFC3339Nano, tg.content.(string))
+		if err != nil {
+			return time.Time{}, ErrCBORInvalidData
+		}
+		return tm, nil
+	case cborTagEpochDateTime:
+		if f, ok := tg.content.(float64); ok {
+			if math.IsNaN(f) || math.Abs(f) > 1<<62 {
+				return time.Time{}, ErrCBORInvalidData
+			}
+			return epochFloatTimeCBOR(f), nil
+		}
+		sec, ok := intCBOR(tg.content)
+		if !ok {
+			return time.Time{}, ErrCBORInvalidData
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	case cborTagExtendedTime:
+		var sec, nsec int64
+		var hasSec bool
+		for _, p := range tg.content.(cborMap) {
+			k, ok := intCBOR(p.key)
+			if !ok {
+				return time.Time{}, ErrCBORInvalidData
+			}
+			n, ok := intCBOR(p.value)
+			if !ok {
+				return time.Time{}, ErrCBORInvalidData
+			}
+			switch k {
+			case 1:
+				sec, hasSec = n, true
+			case -3:
+				nsec = n * int64(time.Millisecond)
+			case -6:
+				nsec = n * int64(time.Microsecond)
+			case -9:
+				nsec = n
+			default:
+				return time.Time{}, ErrCBORUnsupportedItem
+			}
+		}
+		if !hasSec || nsec < 0 || nsec >= int64(time.Second) {
+			return time.Time{}, ErrCBORInvalidData
+		}
+		return time.Unix(sec, nsec).UTC(), nil
+	}
+	return time.Time{}, ErrCBORInvalidData
+}
+
+// readTypedArrayCBOR returns the slice of type t held by a little endian typed array
+func readTypedArrayCBOR(t reflect.Type, b []byte) (reflect.Value, error) {
+	size := int(t.Elem().Size())
+	if len(b)%size != 0 {
+		return reflect.Value{}, ErrCBORInvalidData
+	}
+
+	v := reflect.MakeSlice(t, len(b)/size, len(b)/size)
+	for i := range v.Len() {
+		e, eb := v.Index(i), b[i*size:]
+		switch e.Kind() {
+		case reflect.Int8:
+			e.SetInt(int64(int8(eb[0])))
+		case reflect.Int16:
+			e.SetInt(int64(int16(binary.LittleEndian.Uint16(eb))))
+		case reflect.Int32:
+			e.SetInt(int64(int32(binary.LittleEndian.Uint32(eb))))
+		case reflect.Int64:
+			e.SetInt(int64(binary.LittleEndian.Uint64(eb)))
+		case reflect.Uint16:
+			e.SetUint(uint64(binary.LittleEndian.Uint16(eb)))
+		case reflect.Uint32:
+			e.SetUint(uint64(binary.LittleEndian.Uint32(eb)))
+		case reflect.Uint64:
+			e.SetUint(binary.LittleEndian.Uint64(eb))
+		case reflect.Float32:
+			e.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(eb))))
+		case reflect.Float64:
+			e.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(eb)))
+		}
+	}
+	return v, nil
+}
+
+// readMarshalerCBOR returns the instance of a registered encoding.BinaryUnmarshaler
+// or encoding.TextUnmarshaler, created by appendMarshalerCBOR
+func readMarshalerCBOR(t reflect.Type, id TypeID, x any) (reflect.Value, error) {
+	var b []byte
+	if id == BinaryMarshalerType {
+		b = x.([]byte)
+	} else {
+		b = []byte(x.(string))
+	}
+
+	v, err := newFromMarshaled(t, id, b)
+	if err == errNotUnmarshaler {
+		return reflect.Value{}, ErrCBORTypeNotSerialisable
+	}
+	return v, err
+}
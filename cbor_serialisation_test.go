@@ -0,0 +1,464 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCBORApproach(t *testing.T) {
+
+	for name, prototype := range map[string]any{
+		"testStatus":   testStatus(0),
+		"testLabel":    testLabel(""),
+		"testEvent":    (*testEvent)(nil),
+		"testClick":    testClick{},
+		"testKeyPress": &testKeyPress{},
+		"testEnvelope": testEnvelope{},
+		"testNode":     testNode{},
+		"netip.Addr":   netip.Addr{},
+		"*url.URL":     &url.URL{},
+	} {
+		if err := Register(name, prototype); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", name, err)
+		}
+	}
+
+	tm := time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC)
+	est := tm.In(time.FixedZone("", -5*60*60))
+	s := "Hello World"
+	f := 3.14
+	click := testClick{X: 1, Y: -1, At: tm}
+	key := &testKeyPress{Key: "a", Mods: []string{"ctrl"}}
+
+	tests := []any{
+		int8(-1), int16(-300), int32(math.MinInt32), int64(math.MinInt64), int64(math.MaxInt64),
+		uint8(255), uint16(65535), uint32(math.MaxUint32), uint64(math.MaxUint64), uint64(1),
+		float32(1.1), float32(-0.5), 3.14, 1e300, 1.5, math.Copysign(0, -1), math.Inf(1), math.Inf(-1),
+		float32(math.Inf(-1)), true, false, "", s, "Quote \" and \\ and \n and \x01 and 世界",
+		time.Duration(math.MinInt64), time.Duration(math.MaxInt64), time.Second, tm, est, tm.Truncate(time.Second),
+		&f, &s, (*string)(nil), (*int64)(nil), []byte("abc"), []byte{}, []byte(nil),
+		[]int8{1, -2}, []int16{}, []int32{1}, []int64{1, 2, 3}, []uint16{1}, []uint32{}, []uint64{math.MaxUint64},
+		[]float32{1.5}, []float64{math.NaN(), 1}, []int64(nil), []bool{true, false}, []time.Duration{time.Hour},
+		[]string{"a", "", "bcd"}, []string(nil), [][]byte{[]byte("a"), nil}, []*int64{nil, ptrMD(int64(1))},
+		map[string]int64{"b": 2, "a": 1}, map[int64]string{2: "b", 1: "a"}, map[string][]string{"x": {"y"}},
+		map[any]any{int8(1): "x", "y": []any{int16(2), nil}}, []any{int8(1), "x", nil, 2.5, tm},
+		map[string]any{"a": int64(1), "b": []any{"c"}}, map[string]any{},
+		testStatusClosed, testLabel("GB"), map[testLabel]testStatus{"GB": testStatusActive},
+		click, key, (*testKeyPress)(nil), []testEvent{click, key, nil},
+		testEnvelope{ID: 42, Payload: key, Events: []testEvent{click}, Meta: []string{"x"}, Next: &testEnvelope{ID: 43}},
+		&testNode{Name: "a", Next: &testNode{Name: "b"}},
+		netip.MustParseAddr("192.168.1.1"), &url.URL{Scheme: "https", Host: "example.com"},
+		NewBitSetFromBools([]bool{true, false, true}),
+		testUnregisteredCount(7),
+	}
+
+	deterministic := NewCBORApproach(WithCBORDeterministic())
+
+	// Go types are used so that the same types are returned
+	for _, approach := range []Approach{
+		NewCBORApproach(WithCBORGoTypes()),
+		NewCBORApproach(WithCBORGoTypes(), WithCBORDeterministic()),
+	} {
+		for i, test := range tests {
+			b, name, err := ToBytes(test, WithSerialisationApproach(approach), WithFlateThreshold(-1))
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error packing %T: %v", i, test, err)
+			}
+			if name != CBORApproachName {
+				t.Fatalf("(%d) Expected %s, got: %s", i, CBORApproachName, name)
+			}
+
+			v, err := FromBytesAuto(b, name)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error unpacking %T from %x: %v", i, test, b[1:], err)
+			}
+
+			expected := test
+			if c, ok := test.(testUnregisteredCount); ok {
+				expected = uint32(c) // Unregistered named types are deserialised as their underlying type
+			}
+
+			switch x := expected.(type) {
+			case float64:
+				if math.Float64bits(x) != math.Float64bits(v.(float64)) {
+					t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+				}
+			case []float64:
+				if math.Float64bits(x[0]) != math.Float64bits(v.([]float64)[0]) || x[1] != v.([]float64)[1] {
+					t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+				}
+			case time.Time:
+				// Times are deserialised in UTC
+				if !x.Equal(v.(time.Time)) || v.(time.Time).Location() != time.UTC {
+					t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+				}
+			case testClick, []testEvent, testEnvelope, map[any]any:
+				// Values are compared by their deterministic encoding, as time locations differ
+				if reflect.TypeOf(v) != reflect.TypeOf(x) {
+					t.Fatalf("(%d) Type mismatch: expected %T, got: %T", i, x, v)
+				}
+				vb, _ := deterministic.Pack(v)
+				xb, _ := deterministic.Pack(x)
+				if !bytes.Equal(vb, xb) {
+					t.Fatalf("(%d) Data mismatch: expected %x, got: %x", i, xb, vb)
+				}
+			default:
+				if !reflect.DeepEqual(v, expected) {
+					t.Fatalf("(%d) Data mismatch: expected %#v, got: %#v", i, expected, v)
+				}
+			}
+		}
+	}
+
+	v, err := deterministic.Unpack([]byte{cborNull})
+	if err != nil || v != nil {
+		t.Fatalf("Expected nil, got: %v, %v", v, err)
+	}
+	if b, _ := deterministic.Pack(nil); !bytes.Equal(b, []byte{cborNull}) {
+		t.Fatalf("Expected null, got: %x", b)
+	}
+}
+
+func TestCBORApproachStandardTypes(t *testing.T) {
+
+	if err := Register("testNode", testNode{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	approach := NewCBORApproach()
+
+	// Without Go types, values are returned as the types that decoders return for their items
+	for i, test := range []struct {
+		V        any
+		Expected any
+	}{
+		{int8(-1), int64(-1)},
+		{uint16(65535), int64(65535)},
+		{uint64(math.MaxUint64), uint64(math.MaxUint64)},
+		{float32(1.5), 1.5},
+		{time.Second, int64(time.Second)},
+		{testStatusClosed, int64(testStatusClosed)},
+		{[]time.Duration{time.Hour}, []int64{int64(time.Hour)}},
+		{[]string{"a"}, []any{"a"}},
+		{map[int64]string{1: "a"}, map[any]any{int64(1): "a"}},
+		{testNode{Name: "a"}, map[string]any{"Name": "a", "Next": nil}},
+		{NewBitSetFromBools([]bool{true, false}), []any{true, false}},
+	} {
+		b, err := approach.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if bytes.Contains(b, []byte{0xd8, byte(cborTagObject)}) {
+			t.Fatalf("(%d) Expected no Go type, got: %x", i, b)
+		}
+		v, err := approach.Unpack(b)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, test.Expected) {
+			t.Fatalf("(%d) Data mismatch: expected %#v, got: %#v", i, test.Expected, v)
+		}
+	}
+}
+
+func TestCBORApproachFormat(t *testing.T) {
+
+	approach := NewCBORApproach()
+	deterministic := NewCBORApproach(WithCBORDeterministic())
+	goTypes := NewCBORApproach(WithCBORGoTypes())
+	goTypesDeterministic := NewCBORApproach(WithCBORGoTypes(), WithCBORDeterministic())
+
+	tests := []struct {
+		V             any
+		Expected      string
+		Deterministic string
+		GoTypes       bool
+	}{
+		// Examples from RFC 8949 Appendix A
+		{int64(0), "00", "", false},
+		{int64(-1), "20", "", false},
+		{int64(1000000), "1a000f4240", "", false},
+		{int64(math.MinInt64), "3b7fffffffffffffff", "", false},
+		{1.5, "fb3ff8000000000000", "f93e00", false},
+		{100000.0, "fb40f86a0000000000", "fa47c35000", false},
+		{1.1, "fb3ff199999999999a", "", false},
+		{65504.0, "fb40effc0000000000", "f97bff", false},
+		{5.960464477539063e-8, "fb3e70000000000000", "f90001", false},
+		{0.00006103515625, "fb3f10000000000000", "f90400", false},
+		{-4.0, "fbc010000000000000", "f9c400", false},
+		{math.Inf(1), "fb7ff0000000000000", "f97c00", false},
+		{math.NaN(), "fb7ff8000000000001", "f97e00", false},
+		{true, "f5", "", false},
+		{"IETF", "6449455446", "", false},
+		{[]byte{1, 2, 3, 4}, "4401020304", "", false},
+		{[]any{int64(1), "a"}, "82016161", "", false},
+		{map[string]any{"a": int64(1)}, "a1616101", "", false},
+		{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC), "c11a514b67b0", "", false},
+		{time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC), "c1fb41d452d9ec200000", "", false},
+		// Go specific types use the standard major types
+		{[]int16{1, -1}, "d84d440100ffff", "", false},
+		{[]float32{1.5}, "d855440000c03f", "", false},
+		{int8(1), "01", "", false},
+		{float32(1.5), "fa3fc00000", "f93e00", false},
+		{uint64(1), "01", "", false},
+		{time.Second, "1a3b9aca00", "", false},
+		{[]int64(nil), "f6", "", false},
+		{map[string]int8{"b": 2, "aa": 1, "a": 3}, "", "a361610361620262616101", false},
+		{NewBitSetFromBools([]bool{true, false}), "82f5f4", "", false},
+		// Go types are only tagged when selected
+		{[]int16{1, -1}, "d84d440100ffff", "", true},
+		{int8(1), "d81b8264696e743801", "", true},
+		{float32(1.5), "d81b8267666c6f61743332fa3fc00000", "d81b8267666c6f61743332f93e00", true},
+		{uint64(1), "d81b826675696e74363401", "", true},
+		{[]int64(nil), "d81b82675b5d696e743634f6", "", true},
+		{map[string]int8{"b": 2, "aa": 1, "a": 3}, "", "d81b826f6d61705b737472696e675d696e7438a361610361620262616101", true},
+	}
+
+	for i, test := range tests {
+		approaches := []Approach{approach, deterministic}
+		if test.GoTypes {
+			approaches = []Approach{goTypes, goTypesDeterministic}
+		}
+		for _, c := range []struct {
+			Approach Approach
+			Expected string
+		}{
+			{approaches[0], test.Expected},
+			{approaches[1], test.Deterministic},
+		} {
+			if len(c.Expected) == 0 {
+				c.Expected = test.Expected
+			}
+			if len(c.Expected) == 0 {
+				continue
+			}
+			expected, err := hex.DecodeString(c.Expected)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", i, err)
+			}
+			b, err := c.Approach.Pack(test.V)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", i, err)
+			}
+			if !bytes.Equal(b, expected) {
+				t.Fatalf("(%d) Format mismatch: expected %x, got: %x", i, expected, b)
+			}
+		}
+	}
+
+	// Deterministic output is independent of map iteration order
+	m := map[int64]string{}
+	for i := range int64(100) {
+		m[i*7919] = "x"
+	}
+	expected, err := deterministic.Pack(m)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for range 10 {
+		if b, _ := deterministic.Pack(m); !bytes.Equal(b, expected) {
+			t.Fatalf("Expected deterministic output, got: %x", b)
+		}
+	}
+
+	// Extended times are only used when selected
+	tm := time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC)
+	if b, _ := NewCBORApproach(WithCBORExtendedTime()).Pack(tm); hex.EncodeToString(b) != "d903e9a2011a514b67b0281a1dcd6500" {
+		t.Fatalf("Expected extended time, got: %x", b)
+	}
+}
+
+func TestCBORApproachTime(t *testing.T) {
+
+	// Times with a fractional second are epoch-based dates (tag 1) holding float seconds if
+	// the float holds the time exactly, otherwise extended times (tag 1001), so that times
+	// always round trip with nanoseconds
+	for _, approach := range []Approach{NewCBORApproach(), NewCBORApproach(WithCBORDeterministic())} {
+		for i, test := range []struct {
+			V     time.Time
+			Float bool
+		}{
+			{time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC), true},
+			{time.Date(1960, 1, 1, 0, 0, 0, 25e7, time.UTC), true},
+			{time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC), false},
+			{time.Date(2024, 2, 29, 12, 30, 0, 999999999, time.UTC), false},
+			{time.Date(2024, 2, 29, 12, 30, 0, 1e8, time.UTC), false},
+			{time.Date(1960, 1, 1, 0, 0, 0, 1, time.UTC), false},
+			{time.Date(2262, 4, 11, 23, 47, 16, 854775807, time.UTC), false},
+		} {
+			b, err := approach.Pack(test.V)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", i, err)
+			}
+			if test.Float && b[0] != 0xc1 {
+				t.Fatalf("(%d) Expected tag 1 with float seconds for %v, got: %x", i, test.V, b)
+			}
+			if !test.Float && !bytes.HasPrefix(b, []byte{0xd9, 0x03, 0xe9}) {
+				t.Fatalf("(%d) Expected tag 1001 for %v, got: %x", i, test.V, b)
+			}
+			v, err := approach.Unpack(b)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", i, err)
+			}
+			if !v.(time.Time).Equal(test.V) {
+				t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, test.V, v)
+			}
+		}
+	}
+}
+
+func TestCBORApproachSequence(t *testing.T) {
+
+	// Uncompressed, unencrypted output is a CBOR sequence of the compression flag and the value
+	b, name, err := ToBytes([]any{"a", int64(1)}, WithSerialisationApproach(NewCBORApproach()), WithFlateThreshold(-1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	d := cborDecoder{data: b}
+	for _, expected := range []any{uint64(0), []any{"a", uint64(1)}} {
+		x, err := d.item(0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(x, expected) {
+			t.Fatalf("Data mismatch: expected %v, got: %v", expected, x)
+		}
+	}
+	if d.pos != len(b) {
+		t.Fatalf("Expected the sequence to end, got: %x", b[d.pos:])
+	}
+
+	approach, err := GetApproach(name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if approach.Name() != CBORApproachName {
+		t.Fatalf("Expected %s, got: %s", CBORApproachName, approach.Name())
+	}
+}
+
+func TestCBORApproachDecode(t *testing.T) {
+
+	approach := NewCBORApproach()
+
+	// Items created by other CBOR encoders are deserialised to their default types
+	for i, test := range []struct {
+		Data     string
+		Expected any
+	}{
+		{"9f0102ff", []any{int64(1), int64(2)}},
+		{"7f626865636c6c6fff", "hello"},
+		{"5f4201024103ff", []byte{1, 2, 3}},
+		{"bf616101ff", map[string]any{"a": int64(1)}},
+		{"a10102", map[any]any{int64(1): int64(2)}},
+		{"f93c00", 1.0},
+		{"fa3fc00000", 1.5},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"f7", nil},
+		{"d9d9f701", int64(1)},
+		{"d8404401020304", []byte{1, 2, 3, 4}},
+		{"c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"c1fb41d452d9ec200000", time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC)},
+		{"d903e9a2011a514b67b0221901f4", time.Date(2013, 3, 21, 20, 4, 0, 5e8, time.UTC)},
+		{"d81b826b5b5d74696d652e54696d6581c11a514b67b0", []time.Time{time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)}},
+		{"d81b82665b5d696e7438820120", []int8{1, -1}},
+	} {
+		data, err := hex.DecodeString(test.Data)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		v, err := approach.Unpack(data)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+
+		if tm, ok := test.Expected.(time.Time); ok {
+			if !tm.Equal(v.(time.Time)) {
+				t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, tm, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, test.Expected) {
+			t.Fatalf("(%d) Data mismatch: expected %#v, got: %#v", i, test.Expected, v)
+		}
+	}
+}
+
+func TestCBORApproachErrors(t *testing.T) {
+
+	approach := NewCBORApproach()
+
+	type testUnregistered struct{ A int8 }
+	node := &testNode{Name: "a"}
+	node.Next = node
+
+	for i, test := range []struct {
+		V   any
+		Err error
+	}{
+		{1, ErrCBORTypeNotSerialisable},
+		{testUnregistered{}, ErrCBORTypeNotSerialisable},
+		{"\xff", ErrCBORInvalidUTF8},
+		{node, ErrCBORMaxNestingExceeded},
+	} {
+		if _, err := approach.Pack(test.V); err != test.Err {
+			t.Fatalf("(%d) Expected %v, got: %v", i, test.Err, err)
+		}
+		if approach.IsSerialisable(test.V) {
+			t.Fatalf("(%d) Expected %T to not be serialisable", i, test.V)
+		}
+	}
+
+	for i, test := range []struct {
+		Data string
+		Err  error
+	}{
+		{"", ErrCBORInvalidData},
+		{"0001", ErrCBORInvalidData},
+		{"1c", ErrCBORInvalidData},
+		{"1a0001", ErrCBORInvalidData},
+		{"6261", ErrCBORInvalidData},
+		{"62fffe", ErrCBORInvalidData},
+		{"ff", ErrCBORInvalidData},
+		{"1f", ErrCBORInvalidData},
+		{"9b7fffffffffffffff", ErrCBORInvalidData},
+		{"7f4161ff", ErrCBORInvalidData},
+		{"3bffffffffffffffff", ErrCBORUnsupportedItem},
+		{"f0", ErrCBORUnsupportedItem},
+		{"c24101", ErrCBORUnsupportedItem},
+		{"d81b8264696e743819012c", ErrCBORInvalidData},
+		{"d81b8264696e74386178", ErrCBORInvalidData},
+		{"d81b8266696e74382d3101", ErrUnknownTypeName},
+		{"d81b82686d61705b696e743880", ErrCBORInvalidData},
+		{"d81b8264696e7438", ErrCBORInvalidData},
+		{"d81b8164696e7438", ErrCBORInvalidData},
+		{"d84d43010203", ErrCBORInvalidData},
+		{"d903e9a12800", ErrCBORInvalidData},
+		{"d903e9a201000201", ErrCBORUnsupportedItem},
+		{strings.Repeat("81", maxNestingMD+2) + "01", ErrCBORMaxNestingExceeded},
+	} {
+		data, err := hex.DecodeString(test.Data)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if _, err := approach.Unpack(data); err != test.Err {
+			t.Fatalf("(%d) Expected %v, got: %v", i, test.Err, err)
+		}
+	}
+
+	// Values must be assignable to the interface type
+	if err := Register("testEvent", (*testEvent)(nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := approach.Unpack([]byte("\xd8\x1b\x82\x6b[]testEvent\x81\x01")); err != ErrCBORInvalidData {
+		t.Fatalf("Expected ErrCBORInvalidData, got: %v", err)
+	}
+}
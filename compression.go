@@ -0,0 +1,178 @@
+package serialise
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrInvalidCompressionLevel raised if the compression level is not supported by Flate
+var ErrInvalidCompressionLevel = errors.New("invalid compression level")
+
+// flateWriterPools holds a pool of flate.Writers for each compression level, from
+// flate.HuffmanOnly (-2) to flate.BestCompression (9), as a flate.Writer allocates
+// substantial state (approximately 1MB at higher levels) when it is created
+var flateWriterPools [flate.BestCompression - flate.HuffmanOnly + 1]sync.Pool
+
+// flateReaderPool holds flate readers, which can be reused via flate.Resetter
+var flateReaderPool sync.Pool
+
+// bufferPool holds the buffers into which data is compressed
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// Values of the compression flag that precedes the serialised data
+const (
+	uncompressedFlag    byte = iota // The data is not compressed
+	flateFlag                       // The data is compressed using Flate
+	flateDictionaryFlag             // The data is compressed using Flate with a preset dictionary, whose ID follows the flag
+)
+
+// getFlateWriter returns a flate.Writer for the level and dictionary, that writes to w
+func getFlateWriter(w io.Writer, level int, dict *CompressionDictionary) (*flate.Writer, error) {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return nil, ErrInvalidCompressionLevel
+	}
+
+	pool := &flateWriterPools[level-flate.HuffmanOnly]
+	if dict != nil {
+		pool = &dict.writers[level-flate.HuffmanOnly]
+	}
+
+	if fw, ok := pool.Get().(*flate.Writer); ok {
+		fw.Reset(w) // The writer retains its dictionary
+		return fw, nil
+	}
+	if dict != nil {
+		return flate.NewWriterDict(w, level, dict.data)
+	}
+	return flate.NewWriter(w, level)
+}
+
+// putFlateWriter returns the flate.Writer to the pool for its level and dictionary
+func putFlateWriter(fw *flate.Writer, level int, dict *CompressionDictionary) {
+	if dict != nil {
+		dict.writers[level-flate.HuffmanOnly].Put(fw)
+		return
+	}
+	flateWriterPools[level-flate.HuffmanOnly].Put(fw)
+}
+
+// deflate compresses the data that follows the compression flag at b[n],
+// setting the flag if the compressed data is used
+func deflate(b []byte, n int, o *Options) ([]byte, error) {
+	data := b[n+1:]
+	b[n] = uncompressedFlag
+	if o.shouldCompress(data) { // Trading of time cost of Flate against space... for small []byte cost is too high
+		buf := bufferPool.Get().(*bytes.Buffer)
+		defer bufferPool.Put(buf)
+		buf.Reset()
+
+		var dict *CompressionDictionary
+		if len(o.Dictionaries) > 0 {
+			dict = o.Dictionaries[0]
+		}
+
+		writer, err := getFlateWriter(buf, o.CompressionLevel, dict)
+		if err != nil {
+			return nil, err
+		}
+		defer putFlateWriter(writer, o.CompressionLevel, dict)
+
+		_, err = writer.Write(data)
+		if err != nil {
+			return nil, err
+		}
+		err = writer.Close()
+		if err != nil {
+			return nil, err
+		}
+		bf := buf.Bytes()
+
+		if dict == nil {
+			if o.acceptCompressed(len(data), len(bf)) {
+				b[n] = flateFlag
+				b = append(b[:n+1], bf...)
+			}
+		} else {
+			var id [binary.MaxVarintLen32]byte
+			idb := binary.AppendUvarint(id[:0], uint64(dict.id))
+			if o.acceptCompressed(len(data), len(idb)+len(bf)) {
+				b[n] = flateDictionaryFlag
+				b = append(append(b[:n+1], idb...), bf...)
+			}
+		}
+	}
+	return b, nil
+}
+
+// shouldCompress reports whether compression should be attempted for the data, using the
+// CompressionPolicy if provided, or otherwise the FlateThreshold
+func (o *Options) shouldCompress(data []byte) bool {
+	if o.CompressionPolicy != nil {
+		return o.CompressionPolicy.ShouldCompress(data)
+	}
+	return thresholdPolicy(o.FlateThreshold).ShouldCompress(data)
+}
+
+// acceptCompressed reports whether the compressed data should be used, using the
+// CompressionPolicy if provided, or otherwise the FlateThreshold
+func (o *Options) acceptCompressed(size, compressedSize int) bool {
+	if o.CompressionPolicy != nil {
+		return o.CompressionPolicy.AcceptCompressed(size, compressedSize)
+	}
+	return thresholdPolicy(o.FlateThreshold).AcceptCompressed(size, compressedSize)
+}
+
+// reflate returns the data that follows the compression flag at b[0], decompressing
+// if required using the dictionary identified within the data
+func reflate(b []byte, o *Options) ([]byte, error) {
+	switch b[0] {
+	case flateFlag:
+		return inflate(b[1:], nil, o.MaxSize)
+	case flateDictionaryFlag:
+		id, n := binary.Uvarint(b[1:])
+		if n <= 0 {
+			return nil, ErrUnknownCompressionDictionary
+		}
+		for _, dict := range o.Dictionaries {
+			if uint64(dict.id) == id {
+				return inflate(b[1+n:], dict.data, o.MaxSize)
+			}
+		}
+		return nil, ErrUnknownCompressionDictionary
+	default:
+		return b[1:], nil
+	}
+}
+
+// inflate decompresses the data using a pooled flate reader, returning ErrMaxSizeExceeded
+// without reading further if the decompressed data is longer than maxSize (if greater than 0)
+func inflate(data []byte, dict []byte, maxSize int) ([]byte, error) {
+	br := bytes.NewReader(data)
+
+	r, ok := flateReaderPool.Get().(io.ReadCloser)
+	if ok {
+		if err := r.(flate.Resetter).Reset(br, dict); err != nil {
+			return nil, err
+		}
+	} else {
+		r = flate.NewReaderDict(br, dict)
+	}
+	defer flateReaderPool.Put(r)
+
+	if maxSize > 0 {
+		b, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+		if err == nil && len(b) > maxSize {
+			return nil, ErrMaxSizeExceeded
+		}
+		return b, err
+	}
+	return io.ReadAll(r)
+}
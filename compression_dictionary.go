@@ -0,0 +1,150 @@
+package serialise
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// maxDictionarySize is the size of the Flate window, beyond which a preset dictionary is not used
+const maxDictionarySize = 32 * 1024
+
+// dictionaryGramSize is the length of the substrings that are compared between samples
+// when building a dictionary, which is long enough for Flate to encode as a match
+const dictionaryGramSize = 8
+
+// ErrUnknownCompressionDictionary raised if data was compressed using a dictionary that has not been provided
+var ErrUnknownCompressionDictionary = errors.New("data was compressed using a dictionary that has not been provided")
+
+// ErrNoDictionarySamples raised if a dictionary cannot be built as no samples contain shared content
+var ErrNoDictionarySamples = errors.New("samples do not share content from which to build a dictionary")
+
+// CompressionDictionary is a preset dictionary for Flate, identified by an ID that is
+// recorded in compressed data so that the same dictionary is used for decompression.
+// Dictionaries are most effective for small payloads that share content, such as
+// messages of the same type, and must be distributed to all consumers of the data.
+type CompressionDictionary struct {
+	id      uint32
+	data    []byte
+	writers [flate.BestCompression - flate.HuffmanOnly + 1]sync.Pool // flate.Writers retain their dictionary when Reset
+}
+
+// NewCompressionDictionary creates a CompressionDictionary with the specified ID and content.
+// Only the final 32KB of data is used, as Flate cannot refer to content beyond this.
+func NewCompressionDictionary(id uint32, data []byte) *CompressionDictionary {
+	if len(data) > maxDictionarySize {
+		data = data[len(data)-maxDictionarySize:]
+	}
+	return &CompressionDictionary{
+		id:   id,
+		data: bytes.Clone(data),
+	}
+}
+
+// ID returns the identifier of the dictionary
+func (d *CompressionDictionary) ID() uint32 {
+	return d.id
+}
+
+// Bytes returns the content of the dictionary, allowing it to be stored and distributed.
+// The returned slice must not be modified.
+func (d *CompressionDictionary) Bytes() []byte {
+	return d.data
+}
+
+// BuildCompressionDictionary creates a CompressionDictionary from samples of serialised data,
+// as returned by ToBytes, AppendBytes or ToBytesMany without encryption.  The dictionary holds
+// the content that is shared by the samples, up to maxSize bytes (or 32KB if maxSize is 0),
+// with the most widely shared content placed last, where Flate can refer to it most cheaply.
+func BuildCompressionDictionary(id uint32, samples [][]byte, maxSize int) (*CompressionDictionary, error) {
+	if maxSize <= 0 || maxSize > maxDictionarySize {
+		maxSize = maxDictionarySize
+	}
+
+	payloads := make([][]byte, 0, len(samples))
+	for _, sample := range samples {
+		if len(sample) == 0 {
+			continue
+		}
+		p, err := reflate(sample, &Options{})
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, p)
+	}
+
+	// Count the number of samples in which each substring appears
+	counts := map[string]int{}
+	for _, p := range payloads {
+		seen := map[string]bool{}
+		for i := 0; i+dictionaryGramSize <= len(p); i++ {
+			g := string(p[i : i+dictionaryGramSize])
+			if !seen[g] {
+				seen[g] = true
+				counts[g]++
+			}
+		}
+	}
+
+	// Extract the longest runs of content that are shared with other samples,
+	// scoring each by the extent to which its substrings are shared
+	type segment struct {
+		content string
+		score   int
+	}
+	segments := map[string]int{}
+	for _, p := range payloads {
+		start, score := -1, 0
+		for i := 0; i+dictionaryGramSize <= len(p)+1; i++ {
+			shared := i+dictionaryGramSize <= len(p) && counts[string(p[i:i+dictionaryGramSize])] > 1
+			if shared {
+				if start < 0 {
+					start, score = i, 0
+				}
+				score += counts[string(p[i:i+dictionaryGramSize])]
+				continue
+			}
+			if start >= 0 {
+				content := string(p[start : i-1+dictionaryGramSize])
+				segments[content] = max(segments[content], score)
+				start = -1
+			}
+		}
+	}
+	if len(segments) == 0 {
+		return nil, ErrNoDictionarySamples
+	}
+
+	ordered := make([]segment, 0, len(segments))
+	for content, score := range segments {
+		ordered = append(ordered, segment{content: content, score: score})
+	}
+	slices.SortFunc(ordered, func(a, b segment) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return bytes.Compare([]byte(a.content), []byte(b.content))
+	})
+
+	// Select the highest scoring segments that fit, and then place the highest scoring last
+	var selected []string
+	size := 0
+	for _, s := range ordered {
+		if size+len(s.content) > maxSize {
+			continue
+		}
+		selected = append(selected, s.content)
+		size += len(s.content)
+	}
+	if len(selected) == 0 {
+		return nil, ErrNoDictionarySamples
+	}
+
+	data := make([]byte, 0, size)
+	for i := len(selected) - 1; i >= 0; i-- {
+		data = append(data, selected[i]...)
+	}
+	return NewCompressionDictionary(id, data), nil
+}
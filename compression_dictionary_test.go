@@ -0,0 +1,149 @@
+package serialise
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func testDictionaryMessage(i int) string {
+	return fmt.Sprintf(`{"type":"order","status":"accepted","currency":"GBP","account":"ACC-%05d","quantity":%d}`, i, i%17)
+}
+
+func TestCompressionDictionary(t *testing.T) {
+
+	var samples [][]byte
+	for i := range 50 {
+		b, _, err := ToBytes(testDictionaryMessage(i))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		samples = append(samples, b)
+	}
+
+	dict, err := BuildCompressionDictionary(7, samples, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dict.ID() != 7 {
+		t.Fatalf("ID mismatch: expected 7, got: %d", dict.ID())
+	}
+	if len(dict.Bytes()) == 0 || len(dict.Bytes()) > maxDictionarySize {
+		t.Fatalf("Unexpected dictionary size: %d", len(dict.Bytes()))
+	}
+
+	data := testDictionaryMessage(1000)
+
+	plain, _, err := ToBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for range 3 { // Exercise reuse of pooled writers and readers
+		b, _, err := ToBytes(data, WithCompressionDictionary(dict))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if b[0] != flateDictionaryFlag || b[1] != 7 {
+			t.Fatalf("Expected data to be compressed using the dictionary: got: %v", b[:2])
+		}
+		if len(b) >= len(plain) {
+			t.Fatalf("Expected dictionary to improve compression: got: %d, without: %d", len(b), len(plain))
+		}
+
+		v, err := FromBytes(b, defaultSerialisationApproach, WithCompressionDictionary(dict))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if v.(string) != data {
+			t.Fatalf("Data mismatch: expected %v, got: %v", data, v)
+		}
+	}
+
+	b, _, err := ToBytes(data, WithCompressionDictionary(dict))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := FromBytes(b, defaultSerialisationApproach); err != ErrUnknownCompressionDictionary {
+		t.Fatalf("Expected ErrUnknownCompressionDictionary, got: %v", err)
+	}
+
+	other := NewCompressionDictionary(8, dict.Bytes())
+	if _, err := FromBytes(b, defaultSerialisationApproach, WithCompressionDictionary(other)); err != ErrUnknownCompressionDictionary {
+		t.Fatalf("Expected ErrUnknownCompressionDictionary, got: %v", err)
+	}
+
+	// The dictionary identified by the data is selected
+	v, err := FromBytes(b, defaultSerialisationApproach, WithCompressionDictionary(other), WithCompressionDictionary(dict))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.(string) != data {
+		t.Fatalf("Data mismatch: expected %v, got: %v", data, v)
+	}
+
+	// Data compressed without a dictionary is unaffected
+	v, err = FromBytes(plain, defaultSerialisationApproach, WithCompressionDictionary(dict))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.(string) != data {
+		t.Fatalf("Data mismatch: expected %v, got: %v", data, v)
+	}
+}
+
+func TestCompressionDictionaryMany(t *testing.T) {
+
+	dict := NewCompressionDictionary(300, []byte(testDictionaryMessage(0)))
+
+	data := []any{testDictionaryMessage(1), int64(42), testDictionaryMessage(2)}
+
+	b, _, err := ToBytesMany(data, WithCompressionDictionary(dict))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b[0] != flateDictionaryFlag {
+		t.Fatalf("Expected data to be compressed using the dictionary: got: %d", b[0])
+	}
+
+	v, err := FromBytesMany(b, defaultSerialisationApproach, WithCompressionDictionary(dict))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(v) != len(data) {
+		t.Fatalf("Data size mismatch: expected %v, got: %v", len(data), len(v))
+	}
+	for i := range data {
+		if v[i] != data[i] {
+			t.Fatalf("Data mismatch at %d: expected %v, got: %v", i, data[i], v[i])
+		}
+	}
+
+	if _, err := FromBytesMany(b, defaultSerialisationApproach); err != ErrUnknownCompressionDictionary {
+		t.Fatalf("Expected ErrUnknownCompressionDictionary, got: %v", err)
+	}
+}
+
+func TestNewCompressionDictionary(t *testing.T) {
+
+	data := bytes.Repeat([]byte("abcdefgh"), maxDictionarySize/4)
+	data[len(data)-1] = 'z'
+
+	dict := NewCompressionDictionary(1, data)
+	if len(dict.Bytes()) != maxDictionarySize {
+		t.Fatalf("Expected dictionary to be truncated to %d, got: %d", maxDictionarySize, len(dict.Bytes()))
+	}
+	if dict.Bytes()[maxDictionarySize-1] != 'z' {
+		t.Fatalf("Expected dictionary to retain the final content")
+	}
+
+	data[len(data)-2] = 'y'
+	if dict.Bytes()[maxDictionarySize-2] == 'y' {
+		t.Fatalf("Expected dictionary to copy its content")
+	}
+
+	if _, err := BuildCompressionDictionary(1, [][]byte{{0, 'a'}, {0, 'b'}}, 0); err != ErrNoDictionarySamples {
+		t.Fatalf("Expected ErrNoDictionarySamples, got: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package serialise
+
+import "math"
+
+// CompressionPolicy determines whether serialised data is compressed, allowing the cpu cost
+// of compression to be avoided for data that is unlikely to benefit, such as small payloads
+// or payloads that are already compressed (e.g. JPEG images held as []byte).
+// Implementations must be safe for concurrent use.
+type CompressionPolicy interface {
+	// ShouldCompress reports whether compression should be attempted for the serialised data
+	ShouldCompress(data []byte) bool
+	// AcceptCompressed reports whether compressed data of compressedSize bytes should be
+	// used in place of serialised data of size bytes
+	AcceptCompressed(size, compressedSize int) bool
+}
+
+// defaultMaxEntropy is the entropy, in bits per byte, above which data is considered incompressible
+const defaultMaxEntropy = 7.5
+
+// entropySampleSize is the number of bytes sampled to estimate the entropy of data
+const entropySampleSize = 1024
+
+// entropySampleChunks is the number of evenly spaced chunks sampled from larger data, so that
+// the estimate is not dominated by headers at the start of the data
+const entropySampleChunks = 4
+
+// NewThresholdPolicy returns a CompressionPolicy that compresses data beyond thresholdInBytes,
+// using the compressed data if it is smaller.  If thresholdInBytes is less than 0, no compression
+// will be used, and if it is 0, then defaultFlateThreshold (25) is used.  This is the policy
+// applied by WithFlateThreshold, with the same meaning of thresholdInBytes.
+func NewThresholdPolicy(thresholdInBytes int) CompressionPolicy {
+	return newThresholdPolicy(thresholdInBytes)
+}
+
+type thresholdPolicy int
+
+// newThresholdPolicy returns the policy for thresholdInBytes, with the meaning used by WithFlateThreshold
+func newThresholdPolicy(thresholdInBytes int) thresholdPolicy {
+	switch {
+	case thresholdInBytes == 0:
+		return thresholdPolicy(defaultFlateThreshold)
+	case thresholdInBytes < 0:
+		return -1
+	}
+	return thresholdPolicy(thresholdInBytes)
+}
+
+func (p thresholdPolicy) ShouldCompress(data []byte) bool {
+	return p > -1 && len(data) > int(p)
+}
+
+func (p thresholdPolicy) AcceptCompressed(size, compressedSize int) bool {
+	return compressedSize < size // Sometimes Flate creates a bigger output than its input
+}
+
+// NewEntropyPolicy returns a CompressionPolicy that compresses data beyond thresholdInBytes,
+// provided that the entropy of the data, estimated from a sample, is below maxBitsPerByte.
+// Data that is already compressed or encrypted has an entropy approaching 8 bits per byte,
+// and is not worth compressing.  If maxBitsPerByte is 0, then 7.5 is used.
+// thresholdInBytes has the same meaning as for NewThresholdPolicy.
+func NewEntropyPolicy(thresholdInBytes int, maxBitsPerByte float64) CompressionPolicy {
+	if maxBitsPerByte == 0 {
+		maxBitsPerByte = defaultMaxEntropy
+	}
+	return &entropyPolicy{
+		threshold:  newThresholdPolicy(thresholdInBytes),
+		maxEntropy: maxBitsPerByte,
+	}
+}
+
+type entropyPolicy struct {
+	threshold  thresholdPolicy
+	maxEntropy float64
+}
+
+func (p *entropyPolicy) ShouldCompress(data []byte) bool {
+	return p.threshold.ShouldCompress(data) && estimateEntropy(data) < p.maxEntropy
+}
+
+func (p *entropyPolicy) AcceptCompressed(size, compressedSize int) bool {
+	return p.threshold.AcceptCompressed(size, compressedSize)
+}
+
+// estimateEntropy returns the Shannon entropy, in bits per byte, of a sample of the data
+func estimateEntropy(data []byte) float64 {
+	var counts [256]int
+	n := 0
+
+	if len(data) <= entropySampleSize {
+		for _, c := range data {
+			counts[c]++
+		}
+		n = len(data)
+	} else {
+		chunk := entropySampleSize / entropySampleChunks
+		stride := (len(data) - chunk) / (entropySampleChunks - 1)
+		for i := range entropySampleChunks {
+			for _, c := range data[i*stride : i*stride+chunk] {
+				counts[c]++
+			}
+		}
+		n = chunk * entropySampleChunks
+	}
+
+	entropy := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(n)
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// NewRatioPolicy returns a CompressionPolicy that compresses data beyond thresholdInBytes,
+// using the compressed data only if its size relative to the serialised data is below maxRatio.
+// For example, a maxRatio of 0.9 requires compression to save at least 10%, so that the cpu
+// cost of decompression is not incurred for a marginal saving in space.
+// thresholdInBytes has the same meaning as for NewThresholdPolicy.
+func NewRatioPolicy(thresholdInBytes int, maxRatio float64) CompressionPolicy {
+	return &ratioPolicy{
+		threshold: newThresholdPolicy(thresholdInBytes),
+		maxRatio:  maxRatio,
+	}
+}
+
+type ratioPolicy struct {
+	threshold thresholdPolicy
+	maxRatio  float64
+}
+
+func (p *ratioPolicy) ShouldCompress(data []byte) bool {
+	return p.threshold.ShouldCompress(data)
+}
+
+func (p *ratioPolicy) AcceptCompressed(size, compressedSize int) bool {
+	return float64(compressedSize) < p.maxRatio*float64(size)
+}
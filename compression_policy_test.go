@@ -0,0 +1,166 @@
+package serialise
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// testCountingPolicy records the calls made to the wrapped CompressionPolicy
+type testCountingPolicy struct {
+	CompressionPolicy
+	checked  int
+	accepted int
+}
+
+func (p *testCountingPolicy) ShouldCompress(data []byte) bool {
+	p.checked++
+	return p.CompressionPolicy.ShouldCompress(data)
+}
+
+func (p *testCountingPolicy) AcceptCompressed(size, compressedSize int) bool {
+	p.accepted++
+	return p.CompressionPolicy.AcceptCompressed(size, compressedSize)
+}
+
+func TestCompressionPolicy(t *testing.T) {
+
+	random := make([]byte, 10000)
+	rand.New(rand.NewSource(42)).Read(random)
+
+	text := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 200))
+
+	type testData struct {
+		Name       string
+		Policy     CompressionPolicy
+		V          []byte
+		Compressed bool
+		Attempted  bool
+	}
+
+	tests := []testData{
+		{Name: "threshold text", Policy: NewThresholdPolicy(25), V: text, Compressed: true, Attempted: true},
+		{Name: "threshold small", Policy: NewThresholdPolicy(25), V: []byte("abc"), Compressed: false, Attempted: false},
+		{Name: "threshold disabled", Policy: NewThresholdPolicy(-1), V: text, Compressed: false, Attempted: false},
+		{Name: "threshold random", Policy: NewThresholdPolicy(25), V: random, Compressed: false, Attempted: true},
+		{Name: "entropy text", Policy: NewEntropyPolicy(25, 0), V: text, Compressed: true, Attempted: true},
+		{Name: "entropy random", Policy: NewEntropyPolicy(25, 0), V: random, Compressed: false, Attempted: false},
+		{Name: "ratio text", Policy: NewRatioPolicy(25, 0.5), V: text, Compressed: true, Attempted: true},
+		{Name: "ratio marginal", Policy: NewRatioPolicy(25, 0.1), V: append(bytes.Clone(random), text...), Compressed: false, Attempted: true},
+	}
+
+	for _, test := range tests {
+		policy := &testCountingPolicy{CompressionPolicy: test.Policy}
+
+		b, _, err := ToBytes(test.V, WithCompressionPolicy(policy))
+		if err != nil {
+			t.Fatalf("(%s) Unexpected error: %v", test.Name, err)
+		}
+		if (b[0] == flateFlag) != test.Compressed {
+			t.Fatalf("(%s) Compression mismatch: expected %v, got flag: %d", test.Name, test.Compressed, b[0])
+		}
+		if (policy.accepted > 0) != test.Attempted {
+			t.Fatalf("(%s) Expected compression attempted to be %v", test.Name, test.Attempted)
+		}
+
+		v, err := FromBytes(b, defaultSerialisationApproach)
+		if err != nil {
+			t.Fatalf("(%s) Unexpected error: %v", test.Name, err)
+		}
+		if !bytes.Equal(v.([]byte), test.V) {
+			t.Fatalf("(%s) Data mismatch after round trip", test.Name)
+		}
+	}
+}
+
+func TestCompressionPolicyOptions(t *testing.T) {
+
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+
+	// WithFlateThreshold replaces an earlier policy, and vice versa
+	b, _, err := ToBytes(text, WithCompressionPolicy(NewThresholdPolicy(-1)), WithFlateThreshold(10))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b[0] != flateFlag {
+		t.Fatalf("Expected data to be compressed")
+	}
+
+	b, _, err = ToBytes(text, WithFlateThreshold(10), WithCompressionPolicy(NewThresholdPolicy(-1)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b[0] != uncompressedFlag {
+		t.Fatalf("Expected data to be uncompressed")
+	}
+
+	// A nil policy reverts to the threshold
+	b, _, err = ToBytes(text, WithFlateThreshold(-1), WithCompressionPolicy(nil))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b[0] != uncompressedFlag {
+		t.Fatalf("Expected data to be uncompressed")
+	}
+
+	// The policy applies to the whole output of ToBytesMany
+	policy := &testCountingPolicy{CompressionPolicy: NewThresholdPolicy(10)}
+	b, _, err = ToBytesMany([]any{text, text}, WithCompressionPolicy(policy))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if b[0] != flateFlag || policy.checked != 1 {
+		t.Fatalf("Expected output to be compressed once: got flag: %d, checks: %d", b[0], policy.checked)
+	}
+}
+
+func TestThresholdPolicyMatchesFlateThreshold(t *testing.T) {
+
+	// Replacing WithFlateThreshold with a threshold policy must not change the output
+	for _, threshold := range []int{-10, -1, 0, 1, 10, defaultFlateThreshold, 100} {
+		for _, size := range []int{0, 1, 10, 20, 30, 50, 200} {
+			v := strings.Repeat("a", size)
+
+			expected, _, err := ToBytes(v, WithFlateThreshold(threshold))
+			if err != nil {
+				t.Fatalf("(%d, %d) Unexpected error: %v", threshold, size, err)
+			}
+			b, _, err := ToBytes(v, WithCompressionPolicy(NewThresholdPolicy(threshold)))
+			if err != nil {
+				t.Fatalf("(%d, %d) Unexpected error: %v", threshold, size, err)
+			}
+			if !bytes.Equal(b, expected) {
+				t.Fatalf("(%d, %d) Output mismatch: expected %x, got: %x", threshold, size, expected, b)
+			}
+		}
+	}
+}
+
+func TestEstimateEntropy(t *testing.T) {
+
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(42)).Read(random)
+
+	type testData struct {
+		V   []byte
+		Min float64
+		Max float64
+	}
+
+	tests := []testData{
+		{V: nil, Min: 0, Max: 0},
+		{V: bytes.Repeat([]byte{7}, 5000), Min: 0, Max: 0},
+		{V: bytes.Repeat([]byte{0, 1}, 300), Min: 1, Max: 1},
+		{V: random, Min: 7.5, Max: 8},
+		{V: random[:2000], Min: 7.5, Max: 8},
+		{V: []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)), Min: 3, Max: 5},
+	}
+
+	for i, test := range tests {
+		e := estimateEntropy(test.V)
+		if e < test.Min || e > test.Max {
+			t.Fatalf("(%d) Entropy out of range: expected %v to %v, got: %v", i, test.Min, test.Max, e)
+		}
+	}
+}
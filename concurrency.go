@@ -0,0 +1,58 @@
+package serialise
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// forEachItem calls fn for each index from 0 to n-1, using up to concurrency goroutines.
+// Indices are started in increasing order, and once fn fails no further indices above
+// the failing index are started.  The error returned is that of the lowest failing index,
+// so that the result is the same as processing the items sequentially.
+func forEachItem(n int, concurrency int, fn func(i int) error) error {
+	if concurrency > n {
+		concurrency = n
+	}
+
+	if concurrency <= 1 {
+		for i := range n {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var next atomic.Int64
+	var failedAt atomic.Int64
+	failedAt.Store(int64(n))
+
+	var lck sync.Mutex
+	var firstErr error
+
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := next.Add(1) - 1
+				if i >= int64(n) || i > failedAt.Load() {
+					return
+				}
+
+				if err := fn(int(i)); err != nil {
+					lck.Lock()
+					if i < failedAt.Load() {
+						failedAt.Store(i)
+						firstErr = err
+					}
+					lck.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,46 @@
+package serialise
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachItem(t *testing.T) {
+
+	for _, concurrency := range []int{0, 1, 4, 100} {
+
+		var count atomic.Int64
+		out := make([]int, 50)
+
+		err := forEachItem(len(out), concurrency, func(i int) error {
+			count.Add(1)
+			out[i] = i * 2
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", concurrency, err)
+		}
+		if count.Load() != int64(len(out)) {
+			t.Fatalf("(%d) Unexpected number of calls: %d", concurrency, count.Load())
+		}
+		for i, v := range out {
+			if v != i*2 {
+				t.Fatalf("(%d) Data mismatch at %d: got: %d", concurrency, i, v)
+			}
+		}
+
+		// The error of the lowest failing index is returned
+		for range 20 {
+			err = forEachItem(1000, concurrency, func(i int) error {
+				if i == 137 || i == 500 || i == 999 {
+					return fmt.Errorf("failed at %d", i)
+				}
+				return nil
+			})
+			if err == nil || err.Error() != "failed at 137" {
+				t.Fatalf("(%d) Expected error for the first failing item, got: %v", concurrency, err)
+			}
+		}
+	}
+}
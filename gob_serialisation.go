@@ -0,0 +1,209 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// GobApproachName is the name of the Gob Approach
+const GobApproachName = "GOB"
+
+// Values of the marker byte that precedes data serialised by the Gob Approach
+const (
+	gobNilMarker   byte = iota // The instance is nil, which gob cannot encode
+	gobValueMarker             // A gob stream holding the instance follows the marker
+)
+
+// GobOptions adjust how Gob serialisation is performed
+type GobOptions struct {
+	// Types are the concrete types to be registered with encoding/gob
+	Types []any
+}
+
+// WithGobTypes registers the concrete types of the values with encoding/gob, using gob.Register,
+// so that instances of the types can be serialised.  As with gob.Register, registration is
+// process-wide and must be consistent across all processes that exchange serialised data.
+func WithGobTypes(values ...any) func(*GobOptions) {
+	return func(o *GobOptions) {
+		o.Types = append(o.Types, values...)
+	}
+}
+
+// ErrGobTypeRegistration is raised if a type cannot be registered with encoding/gob, which will
+// be the case if the type or its name has already been registered differently
+var ErrGobTypeRegistration = errors.New("type cannot be registered with encoding/gob")
+
+// ErrGobInvalidData is raised if the data was not created by the Gob approach
+var ErrGobInvalidData = errors.New("invalid data provided. data must be created by the Gob approach")
+
+// NewGobApproach creates an instance of Gob serialisation, which uses encoding/gob to serialise
+// Go types that are not supported by MinData, such as structs that have not been registered
+// using Register.  Instances are serialised as the empty interface, so their concrete types
+// must be registered, using WithGobTypes or gob.Register; builtin types, []any, map[string]any,
+// time.Time and time.Duration are registered by the approach when it is first used.
+//
+// The approach is not registered in the Approach registry by default, as registration with
+// encoding/gob is process-wide; use RegisterApproach so that FromBytesAuto can select it.
+//
+// The usual gob behaviour applies: pointers are serialised as the values they point to,
+// and so are deserialised as values, and nil pointers cannot be serialised.
+// ErrGobTypeRegistration is returned if any of the types cannot be registered.
+func NewGobApproach(opts ...func(*GobOptions)) (Approach, error) {
+	o := GobOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for _, v := range o.Types {
+		if err := registerGobType(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return &gobApproach{}, nil
+}
+
+type gobApproach struct{}
+
+// gobDefaultTypes registers the default types with encoding/gob once, when a Gob approach is
+// first used, so that importing the package does not alter the process-wide gob registry
+var gobDefaultTypes = sync.OnceValue(func() error {
+	for _, v := range []any{[]any{}, map[string]any{}, time.Time{}, time.Duration(0)} {
+		// Registration fails if the application has already registered the type under another
+		// name, in which case the type can still be encoded
+		if err := registerGobType(v); err != nil && !isGobEncodable(v) {
+			return err
+		}
+	}
+	return nil
+})
+
+// gobSerialisableTypes caches the types that are known to be serialisable
+var gobSerialisableTypes sync.Map
+
+// registerGobType registers the concrete type of v with encoding/gob, which panics on conflicts
+func registerGobType(v any) (e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = ErrGobTypeRegistration
+		}
+	}()
+
+	if v == nil {
+		return ErrGobTypeRegistration
+	}
+	gob.Register(v)
+	return nil
+}
+
+// isGobEncodable returns true if v can be encoded as an interface value, which requires its
+// concrete type to be registered with encoding/gob
+func isGobEncodable(v any) bool {
+	return gob.NewEncoder(io.Discard).Encode(&v) == nil
+}
+
+// Name of the approach
+func (g *gobApproach) Name() string {
+	return GobApproachName
+}
+
+// IsSerialisable returns true if an instance of the specified type can be serialised.
+// encoding/gob cannot report whether a type is registered and supported without encoding
+// it, so a zero value of the type is encoded (and discarded) the first time that the type is
+// checked, and the result is cached; v itself is not encoded.  Values held within interface
+// types, such as the elements of []any, are therefore not checked.
+func (g *gobApproach) IsSerialisable(v any) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	if gobDefaultTypes() != nil {
+		return false
+	}
+	if v == nil {
+		return true
+	}
+
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return false
+	}
+	if _, ok := gobSerialisableTypes.Load(t); ok {
+		return true
+	}
+
+	var zero any
+	if t.Kind() == reflect.Pointer {
+		zero = reflect.New(t.Elem()).Interface()
+	} else {
+		zero = reflect.Zero(t).Interface()
+	}
+	if !isGobEncodable(zero) {
+		return false
+	}
+
+	gobSerialisableTypes.Store(t, true)
+	return true
+}
+
+// Pack serialises the instance to a byte slice
+func (g *gobApproach) Pack(data any) ([]byte, error) {
+	if err := gobDefaultTypes(); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return []byte{gobNilMarker}, nil
+	}
+
+	buf := bytes.NewBuffer([]byte{gobValueMarker})
+	if err := gob.NewEncoder(buf).Encode(&data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unpack deserialises an instance from the byte slice
+func (g *gobApproach) Unpack(data []byte) (output any, e error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = nil
+			e = ErrGobInvalidData
+		}
+	}()
+
+	if err := gobDefaultTypes(); err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrGobInvalidData
+	}
+
+	switch data[0] {
+	case gobNilMarker:
+		if len(data) != 1 {
+			return nil, ErrGobInvalidData
+		}
+		return nil, nil
+	case gobValueMarker:
+		r := bytes.NewReader(data[1:])
+
+		var v any
+		if err := gob.NewDecoder(r).Decode(&v); err != nil {
+			return nil, err
+		}
+		if r.Len() != 0 {
+			return nil, ErrGobInvalidData
+		}
+		return v, nil
+	default:
+		return nil, ErrGobInvalidData
+	}
+}
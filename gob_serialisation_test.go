@@ -0,0 +1,180 @@
+package serialise
+
+import (
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testGobOrder struct {
+	ID     int
+	Lines  map[string][]float64
+	Placed time.Time
+	Notes  *string
+}
+
+type testGobUnexported struct {
+	id int
+}
+
+// testGobTypes makes the types created by newTestGobType unique to each run of a test,
+// as registration with encoding/gob is process-wide and permanent
+var testGobTypes atomic.Int64
+
+// newTestGobType returns an instance of a struct type that is not registered with gob
+func newTestGobType() any {
+	f := reflect.StructField{Name: fmt.Sprintf("A%d", testGobTypes.Add(1)), Type: reflect.TypeFor[int]()}
+	return reflect.New(reflect.StructOf([]reflect.StructField{f})).Elem().Interface()
+}
+
+func TestGobApproach(t *testing.T) {
+
+	// Registration with encoding/gob is process-wide, so the approach is only registered on request
+	if _, err := GetApproach(GobApproachName); err == nil {
+		t.Fatalf("Expected %s to not be registered by default", GobApproachName)
+	}
+
+	approach, err := NewGobApproach(WithGobTypes(testGobOrder{}, []testGobOrder{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r := NewRegistry(nil)
+	r.Register(approach)
+
+	notes := "fragile"
+	order := testGobOrder{
+		ID:     42,
+		Lines:  map[string][]float64{"a": {1.5, 2}},
+		Placed: time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC),
+		Notes:  &notes,
+	}
+
+	tests := []struct {
+		V        any
+		Expected any
+	}{
+		{nil, nil},
+		{42, 42},
+		{"Hello World", "Hello World"},
+		{[]byte("abc"), []byte("abc")},
+		{[]any{int8(1), "x"}, []any{int8(1), "x"}},
+		{map[string]any{"a": 1.5}, map[string]any{"a": 1.5}},
+		{time.Second, time.Second},
+		{order, order},
+		{&order, order}, // Pointers are deserialised as values
+		{[]testGobOrder{order, {ID: 1}}, []testGobOrder{order, {ID: 1}}},
+	}
+
+	for i, test := range tests {
+		if !approach.IsSerialisable(test.V) {
+			t.Fatalf("(%d) Expected %T to be serialisable", i, test.V)
+		}
+
+		b, name, err := ToBytes(test.V, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if name != GobApproachName {
+			t.Fatalf("(%d) Expected %s, got: %s", i, GobApproachName, name)
+		}
+
+		v, err := FromBytesAuto(b, name, WithRegistry(r))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, test.Expected) {
+			t.Fatalf("(%d) Data mismatch: expected %#v, got: %#v", i, test.Expected, v)
+		}
+	}
+
+	// Gob uses the same framing as other Approaches for batches
+	items := []any{order, int64(1)}
+	b, _, err := ToBytesMany(items, WithSerialisationApproach(approach))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vs, err := FromBytesMany(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(vs, items) {
+		t.Fatalf("Data mismatch: expected %#v, got: %#v", items, vs)
+	}
+}
+
+func TestGobApproachErrors(t *testing.T) {
+
+	approach, err := NewGobApproach()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	unregistered := newTestGobType()
+
+	for i, test := range []any{
+		unregistered,
+		(*testGobOrder)(nil),
+		func() {},
+		make(chan int),
+	} {
+		if approach.IsSerialisable(test) {
+			t.Fatalf("(%d) Expected %T to not be serialisable", i, test)
+		}
+		if _, err := approach.Pack(test); err == nil {
+			t.Fatalf("(%d) Expected an error packing %T", i, test)
+		}
+	}
+
+	// Registration makes a type serialisable
+	if _, err := NewGobApproach(WithGobTypes(unregistered)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !approach.IsSerialisable(unregistered) {
+		t.Fatalf("Expected registered type to be serialisable")
+	}
+
+	// Types without exported fields are not supported by gob
+	if _, err := NewGobApproach(WithGobTypes(testGobUnexported{})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if approach.IsSerialisable(testGobUnexported{}) {
+		t.Fatalf("Expected type without exported fields to not be serialisable")
+	}
+
+	if _, err := NewGobApproach(WithGobTypes(nil)); err != ErrGobTypeRegistration {
+		t.Fatalf("Expected ErrGobTypeRegistration, got: %v", err)
+	}
+
+	// Types registered with gob under a different name cannot be registered again
+	renamed := newTestGobType()
+	gob.RegisterName(fmt.Sprintf("testGobRenamed%d", testGobTypes.Load()), renamed)
+	if _, err := NewGobApproach(WithGobTypes(renamed)); err != ErrGobTypeRegistration {
+		t.Fatalf("Expected ErrGobTypeRegistration, got: %v", err)
+	}
+	if !isGobEncodable(renamed) {
+		t.Fatalf("Expected a type registered under a different name to be encodable")
+	}
+	m := reflect.MakeMap(reflect.MapOf(reflect.TypeFor[string](), reflect.TypeOf(unregistered))).Interface()
+	if _, err := NewGobApproach(WithGobTypes(m, int32(0))); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, err := approach.Pack(int64(1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, test := range [][]byte{{}, {gobNilMarker, 0}, {2}, b[:len(b)-1], append(b, 0)} {
+		if _, err := approach.Unpack(test); err == nil {
+			t.Fatalf("(%d) Expected an error", i)
+		}
+	}
+	for i, test := range [][]byte{{}, {gobNilMarker, 0}, {2}, append(b, 0)} {
+		if _, err := approach.Unpack(test); err != ErrGobInvalidData {
+			t.Fatalf("(%d) Expected ErrGobInvalidData, got: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package serialise
+
+import "sync"
+
+// Interner provides the string value for the bytes of a deserialised string, allowing
+// equal strings to share memory.  Implementations must be safe for concurrent use,
+// and must not retain b, which may be modified after Intern returns.
+type Interner interface {
+	// Intern returns a string equal to b
+	Intern(b []byte) string
+}
+
+// InternPool is an Interner that retains the strings it has provided, so that
+// subsequent requests for equal strings return the retained string
+type InternPool struct {
+	lck   sync.RWMutex
+	m     map[string]string
+	limit int
+}
+
+// NewInternPool creates an InternPool that retains up to limit strings.
+// Once the limit is reached, strings that are not retained are returned as copies.
+// A limit of 0 or less indicates that the number of strings is not limited.
+func NewInternPool(limit int) *InternPool {
+	return &InternPool{
+		m:     map[string]string{},
+		limit: limit,
+	}
+}
+
+// Intern returns the retained string equal to b, retaining a copy of b if there is none
+func (p *InternPool) Intern(b []byte) string {
+	p.lck.RLock()
+	s, ok := p.m[string(b)]
+	p.lck.RUnlock()
+	if ok {
+		return s
+	}
+
+	p.lck.Lock()
+	defer p.lck.Unlock()
+
+	if s, ok := p.m[string(b)]; ok {
+		return s
+	}
+
+	s = string(b)
+	if p.limit <= 0 || len(p.m) < p.limit {
+		p.m[s] = s
+	}
+	return s
+}
+
+// Len returns the number of strings retained by the InternPool
+func (p *InternPool) Len() int {
+	p.lck.RLock()
+	defer p.lck.RUnlock()
+
+	return len(p.m)
+}
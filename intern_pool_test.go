@@ -0,0 +1,51 @@
+package serialise
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+func TestInternPool(t *testing.T) {
+
+	pool := NewInternPool(0)
+
+	b := []byte("Hello")
+	s1 := pool.Intern(b)
+	b[0] = 'J'
+	if s1 != "Hello" {
+		t.Fatalf("Interned string aliases the input: %q", s1)
+	}
+
+	s2 := pool.Intern([]byte("Hello"))
+	if unsafe.StringData(s1) != unsafe.StringData(s2) {
+		t.Fatal("Expected equal strings to be shared")
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				s := pool.Intern([]byte(fmt.Sprintf("%d", (i+j)%50)))
+				if s != fmt.Sprintf("%d", (i+j)%50) {
+					t.Errorf("Unexpected string: %s", s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pool.Len() != 51 {
+		t.Fatalf("Unexpected pool size: %d", pool.Len())
+	}
+
+	// Strings beyond the limit are not retained
+	limited := NewInternPool(1)
+	limited.Intern([]byte("a"))
+	if s := limited.Intern([]byte("b")); s != "b" || limited.Len() != 1 {
+		t.Fatalf("Unexpected result beyond limit: %q, %d", s, limited.Len())
+	}
+}
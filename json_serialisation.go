@@ -0,0 +1,601 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONApproachName is the name of the JSON Approach in the Approach registry
+const JSONApproachName = "JSON"
+
+// NewJSONApproach creates an instance of JSON serialisation, which serialises the same
+// types as MinData to human readable JSON, for debugging and interoperability with
+// services that are not written in Go.
+//
+// Values are serialised as an object holding the type and value, e.g.
+//
+//	{"type":"[]int64","value":["1","2"]}
+//
+// so that the same Go types are returned by Unpack.  Types are described using Go syntax,
+// with the names of types registered using Register or the TypeID of types registered using
+// RegisterType (e.g. "#64").  Following the conventions of the protobuf JSON mapping,
+// int64 and uint64 values are serialised as strings, as are float values that are NaN
+// or infinite, []byte values are serialised as base64 strings, and maps with keys that
+// are not strings are serialised as an array of key and value pairs.  Values held by
+// interface types, such as the elements of []any, are serialised as typed objects.
+//
+// Strings must be valid UTF-8, and pointers are serialised by value, so that shared
+// pointers are deserialised as separate copies and cyclic references are not supported.
+func NewJSONApproach() Approach {
+	return &jsonApproach{}
+}
+
+type jsonApproach struct{}
+
+// ErrJSONTypeNotSerialisable is raised if a variable type is not serialisable by the JSON approach
+var ErrJSONTypeNotSerialisable = errors.New("type of argument is not serialisable to JSON")
+
+// ErrJSONInvalidUTF8 is raised if a string to be serialised by the JSON approach is not valid UTF-8
+var ErrJSONInvalidUTF8 = errors.New("string is not valid UTF-8 and cannot be serialised to JSON")
+
+// ErrJSONMaxNestingExceeded is raised if values are nested beyond the supported depth,
+// which will be the case if a cyclic reference is serialised by the JSON approach
+var ErrJSONMaxNestingExceeded = errors.New("maximum nesting depth exceeded - cyclic references are not supported by JSON")
+
+// ErrJSONInvalidData is raised if the data was not created by the JSON approach
+var ErrJSONInvalidData = errors.New("invalid data provided. data must be created by the JSON approach")
+
+// Name of the approach
+func (j *jsonApproach) Name() string {
+	return JSONApproachName
+}
+
+// IsSerialisable returns true if an instance of the specified type
+// can be serialised
+func (j *jsonApproach) IsSerialisable(v any) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	_, err := j.Pack(v)
+	return err == nil
+}
+
+// Pack serialises the instance to a byte slice
+func (j *jsonApproach) Pack(data any) ([]byte, error) {
+	if data == nil {
+		return []byte("null"), nil
+	}
+	return appendTaggedJSON(nil, reflect.ValueOf(data), 0)
+}
+
+// Unpack deserialises an instance from the byte slice
+func (j *jsonApproach) Unpack(data []byte) (output any, e error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = nil
+			e = ErrJSONInvalidData
+		}
+	}()
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var x any
+	if err := dec.Decode(&x); err != nil {
+		return nil, ErrJSONInvalidData
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, ErrJSONInvalidData
+	}
+
+	if x == nil {
+		return nil, nil
+	}
+	v, err := readTaggedJSON(x, 0)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+// appendTaggedJSON appends the value as an object holding its type and value
+func appendTaggedJSON(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	name, ok := goTypeName(v.Type())
+	if !ok {
+		return nil, ErrJSONTypeNotSerialisable
+	}
+
+	b = append(b, `{"type":`...)
+	b = appendStringJSON(b, name)
+	b = append(b, `,"value":`...)
+
+	var err error
+	if b, err = appendValueJSON(b, v, depth); err != nil {
+		return nil, err
+	}
+	return append(b, '}'), nil
+}
+
+// appendValueJSON appends the value, which is described by the type of v
+func appendValueJSON(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	if depth > maxNestingMD {
+		return nil, ErrJSONMaxNestingExceeded
+	}
+	depth++
+
+	t := v.Type()
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return append(b, "null"...), nil
+		}
+	}
+
+	switch t {
+	case timeType:
+		tm := v.Interface().(time.Time)
+		if _, offset := tm.Zone(); offset%60 != 0 {
+			tm = tm.UTC() // RFC 3339 offsets cannot represent seconds
+		}
+		return appendStringJSON(b, tm.Format(time.RFC3339Nano)), nil
+	case durationType:
+		return appendStringJSON(b, time.Duration(v.Int()).String()), nil
+	case bitSetType:
+		bs := v.Interface().(BitSet)
+		return appendValueJSON(b, reflect.ValueOf(bs.Bools()), depth)
+	}
+
+	if c, ok := codecForType(t); ok {
+		cb, err := c.pack(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return appendStringJSON(b, base64.StdEncoding.EncodeToString(cb)), nil
+	}
+
+	if _, ok := marshalerTypeIDMD(t); ok {
+		if _, ok := registeredName(t); ok {
+			return appendMarshalerJSON(b, v.Interface())
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.AppendBool(b, v.Bool()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return strconv.AppendInt(b, v.Int(), 10), nil
+	case reflect.Int64:
+		return appendStringJSON(b, strconv.FormatInt(v.Int(), 10)), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return strconv.AppendUint(b, v.Uint(), 10), nil
+	case reflect.Uint64:
+		return appendStringJSON(b, strconv.FormatUint(v.Uint(), 10)), nil
+	case reflect.Float32:
+		return appendFloatJSON(b, v.Float(), 32), nil
+	case reflect.Float64:
+		return appendFloatJSON(b, v.Float(), 64), nil
+	case reflect.String:
+		if !utf8.ValidString(v.String()) {
+			return nil, ErrJSONInvalidUTF8
+		}
+		return appendStringJSON(b, v.String()), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return appendStringJSON(b, base64.StdEncoding.EncodeToString(v.Bytes())), nil
+		}
+		return appendSliceJSON(b, v, depth)
+	case reflect.Map:
+		return appendMapJSON(b, v, depth)
+	case reflect.Struct:
+		return appendStructJSON(b, v, depth)
+	case reflect.Pointer:
+		return appendValueJSON(b, v.Elem(), depth)
+	case reflect.Interface:
+		return appendTaggedJSON(b, v.Elem(), depth)
+	default:
+		return nil, ErrJSONTypeNotSerialisable
+	}
+}
+
+// appendFloatJSON appends the float as a number, or as a string if it is NaN or infinite
+func appendFloatJSON(b []byte, f float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(b, `"NaN"`...)
+	case math.IsInf(f, 1):
+		return append(b, `"Infinity"`...)
+	case math.IsInf(f, -1):
+		return append(b, `"-Infinity"`...)
+	default:
+		return strconv.AppendFloat(b, f, 'g', -1, bitSize)
+	}
+}
+
+// appendStringJSON appends the string, which must be valid UTF-8, as a JSON string
+func appendStringJSON(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c == '\n':
+			b = append(b, '\\', 'n')
+		case c == '\r':
+			b = append(b, '\\', 'r')
+		case c == '\t':
+			b = append(b, '\\', 't')
+		case c < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			b = append(b, c)
+		}
+	}
+	return append(b, '"')
+}
+
+// appendSliceJSON appends the elements of the slice as an array
+func appendSliceJSON(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	var err error
+	b = append(b, '[')
+	for i := range v.Len() {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if b, err = appendValueJSON(b, v.Index(i), depth); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// appendMapJSON appends a map with string keys as an object, and other maps as an
+// array of key and value pairs.  Entries are sorted by key, so that the output is deterministic.
+func appendMapJSON(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	type entry struct {
+		key   []byte
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := appendValueJSON(nil, iter.Key(), depth)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return bytes.Compare(a.key, b.key)
+	})
+
+	stringKeys := v.Type().Key().Kind() == reflect.String
+
+	var err error
+	if stringKeys {
+		b = append(b, '{')
+	} else {
+		b = append(b, '[')
+	}
+	for i, e := range entries {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if stringKeys {
+			b = append(append(b, e.key...), ':')
+		} else {
+			b = append(append(append(b, '['), e.key...), ',')
+		}
+		if b, err = appendValueJSON(b, e.value, depth); err != nil {
+			return nil, err
+		}
+		if !stringKeys {
+			b = append(b, ']')
+		}
+	}
+	if stringKeys {
+		return append(b, '}'), nil
+	}
+	return append(b, ']'), nil
+}
+
+// appendStructJSON appends the exported fields of a registered struct as an object
+func appendStructJSON(b []byte, v reflect.Value, depth int) ([]byte, error) {
+	var err error
+	b = append(b, '{')
+	first := true
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if !first {
+			b = append(b, ',')
+		}
+		first = false
+
+		b = append(appendStringJSON(b, f.Name), ':')
+		if b, err = appendValueJSON(b, v.Field(i), depth); err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+// appendMarshalerJSON appends the output of a registered encoding.BinaryMarshaler
+// as a base64 string, or of a registered encoding.TextMarshaler as a string
+func appendMarshalerJSON(b []byte, data any) ([]byte, error) {
+	switch v := data.(type) {
+	case encoding.BinaryMarshaler:
+		mb, err := v.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return appendStringJSON(b, base64.StdEncoding.EncodeToString(mb)), nil
+	case encoding.TextMarshaler:
+		mb, err := v.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(mb) {
+			return nil, ErrJSONInvalidUTF8
+		}
+		return appendStringJSON(b, string(mb)), nil
+	default:
+		return nil, ErrJSONTypeNotSerialisable
+	}
+}
+
+// readTaggedJSON returns the value of an object created by appendTaggedJSON
+func readTaggedJSON(x any, depth int) (reflect.Value, error) {
+	obj, ok := x.(map[string]any)
+	if !ok {
+		return reflect.Value{}, ErrJSONInvalidData
+	}
+	name, ok := obj["type"].(string)
+	if !ok {
+		return reflect.Value{}, ErrJSONInvalidData
+	}
+
+	t, err := parseGoTypeName(name)
+	if errors.Is(err, errInvalidTypeName) {
+		return reflect.Value{}, ErrJSONInvalidData
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return readValueJSON(t, obj["value"], depth)
+}
+
+// readValueJSON returns the value of type t, from the decoded JSON created by appendValueJSON
+func readValueJSON(t reflect.Type, x any, depth int) (reflect.Value, error) {
+	if depth > maxNestingMD {
+		return reflect.Value{}, ErrJSONMaxNestingExceeded
+	}
+	depth++
+
+	v := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		if x == nil {
+			return v, nil
+		}
+	}
+
+	switch t {
+	case timeType:
+		tm, err := time.Parse(time.RFC3339Nano, x.(string))
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.Set(reflect.ValueOf(tm))
+		return v, nil
+	case durationType:
+		d, err := time.ParseDuration(x.(string))
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	case bitSetType:
+		bools, err := readValueJSON(reflect.TypeFor[[]bool](), x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(*NewBitSetFromBools(bools.Interface().([]bool))))
+		return v, nil
+	}
+
+	if c, ok := codecForType(t); ok {
+		b, err := base64.StdEncoding.DecodeString(x.(string))
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		cv, err := c.unpack(b)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v.Set(reflect.ValueOf(cv))
+		return v, nil
+	}
+
+	if id, ok := marshalerTypeIDMD(t); ok {
+		if _, ok := registeredName(t); ok {
+			return readMarshalerJSON(t, id, x.(string))
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(x.(bool))
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(numberJSON(x), 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.SetInt(i)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(numberJSON(x), 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := readFloatJSON(x, t.Bits())
+		if err != nil {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(x.(string))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			b, err := base64.StdEncoding.DecodeString(x.(string))
+			if err != nil {
+				return reflect.Value{}, ErrJSONInvalidData
+			}
+			v.SetBytes(b)
+			return v, nil
+		}
+		items := x.([]any)
+		v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		for i, item := range items {
+			iv, err := readValueJSON(t.Elem(), item, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			v.Index(i).Set(iv)
+		}
+	case reflect.Map:
+		return readMapJSON(t, x, depth)
+	case reflect.Struct:
+		obj := x.(map[string]any)
+		for name, fx := range obj {
+			f := v.FieldByName(name)
+			if !f.IsValid() || !f.CanSet() {
+				continue // Fields that are no longer present within the struct type are ignored
+			}
+			fv, err := readValueJSON(f.Type(), fx, depth)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			f.Set(fv)
+		}
+	case reflect.Pointer:
+		ev, err := readValueJSON(t.Elem(), x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(ev)
+		v.Set(p)
+	case reflect.Interface:
+		iv, err := readTaggedJSON(x, depth)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !iv.Type().AssignableTo(t) {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		v.Set(iv)
+	default:
+		return reflect.Value{}, ErrJSONInvalidData
+	}
+	return v, nil
+}
+
+// numberJSON returns the text of an integer, which is either a number or a string
+func numberJSON(x any) string {
+	if n, ok := x.(json.Number); ok {
+		return string(n)
+	}
+	return x.(string)
+}
+
+// readFloatJSON returns the value of a float created by appendFloatJSON
+func readFloatJSON(x any, bitSize int) (float64, error) {
+	switch x {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	}
+	return strconv.ParseFloat(string(x.(json.Number)), bitSize)
+}
+
+// readMapJSON returns the value of a map created by appendMapJSON
+func readMapJSON(t reflect.Type, x any, depth int) (reflect.Value, error) {
+	v := reflect.MakeMap(t)
+
+	set := func(kx, vx any) error {
+		kv, err := readValueJSON(t.Key(), kx, depth)
+		if err != nil {
+			return err
+		}
+		ev, err := readValueJSON(t.Elem(), vx, depth)
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(kv, ev)
+		return nil
+	}
+
+	if t.Key().Kind() == reflect.String {
+		for k, vx := range x.(map[string]any) {
+			if err := set(k, vx); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		return v, nil
+	}
+
+	for _, pair := range x.([]any) {
+		kv := pair.([]any)
+		if len(kv) != 2 {
+			return reflect.Value{}, ErrJSONInvalidData
+		}
+		if err := set(kv[0], kv[1]); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return v, nil
+}
+
+// readMarshalerJSON returns the instance of a registered encoding.BinaryUnmarshaler
+// or encoding.TextUnmarshaler, created by appendMarshalerJSON
+func readMarshalerJSON(t reflect.Type, id TypeID, s string) (reflect.Value, error) {
+	b := []byte(s)
+	if id == BinaryMarshalerType {
+		var err error
+		if b, err = base64.StdEncoding.DecodeString(s); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	v, err := newFromMarshaled(t, id, b)
+	if err == errNotUnmarshaler {
+		return reflect.Value{}, ErrJSONTypeNotSerialisable
+	}
+	return v, err
+}
@@ -0,0 +1,227 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONApproach(t *testing.T) {
+
+	for name, prototype := range map[string]any{
+		"testStatus":   testStatus(0),
+		"testLabel":    testLabel(""),
+		"testEvent":    (*testEvent)(nil),
+		"testClick":    testClick{},
+		"testKeyPress": &testKeyPress{},
+		"testEnvelope": testEnvelope{},
+		"testNode":     testNode{},
+		"netip.Addr":   netip.Addr{},
+		"*url.URL":     &url.URL{},
+	} {
+		if err := Register(name, prototype); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", name, err)
+		}
+	}
+
+	tm := time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC)
+	est := tm.In(time.FixedZone("", -5*60*60))
+	s := "Hello World"
+	f := 3.14
+	click := testClick{X: 1, Y: -1, At: tm}
+	key := &testKeyPress{Key: "a", Mods: []string{"ctrl"}}
+
+	tests := []any{
+		int8(-1), int16(-300), int32(math.MinInt32), int64(math.MinInt64), int64(math.MaxInt64),
+		uint8(255), uint16(65535), uint32(math.MaxUint32), uint64(math.MaxUint64),
+		float32(1.1), float32(-0.5), 3.14, 1e300, math.Copysign(0, -1), math.Inf(1), math.Inf(-1),
+		float32(math.Inf(-1)), true, false, "", s, "Quote \" and \\ and \n and \x01 and 世界",
+		time.Duration(math.MinInt64), time.Duration(math.MaxInt64), time.Second, tm, est,
+		&f, &s, (*string)(nil), (*int64)(nil), []byte("abc"), []byte{}, []byte(nil),
+		[]int8{1, -2}, []int16{}, []int32{1}, []int64{1, 2, 3}, []uint16{1}, []uint32{}, []uint64{math.MaxUint64},
+		[]float32{1.5}, []float64{math.NaN(), 1}, []bool{true, false}, []time.Duration{time.Hour},
+		[]string{"a", "", "bcd"}, []string(nil), [][]byte{[]byte("a"), nil}, []*int64{nil, ptrMD(int64(1))},
+		map[string]int64{"b": 2, "a": 1}, map[int64]string{2: "b", 1: "a"}, map[string][]string{"x": {"y"}},
+		map[any]any{int8(1): "x", "y": []any{int16(2), nil}}, []any{int8(1), "x", nil, 2.5, tm},
+		testStatusClosed, testLabel("GB"), map[testLabel]testStatus{"GB": testStatusActive},
+		click, key, (*testKeyPress)(nil), []testEvent{click, key, nil},
+		testEnvelope{ID: 42, Payload: key, Events: []testEvent{click}, Meta: []string{"x"}, Next: &testEnvelope{ID: 43}},
+		&testNode{Name: "a", Next: &testNode{Name: "b"}},
+		netip.MustParseAddr("192.168.1.1"), &url.URL{Scheme: "https", Host: "example.com"},
+		NewBitSetFromBools([]bool{true, false, true}),
+		testUnregisteredCount(7),
+	}
+
+	approach := NewJSONApproach()
+
+	for i, test := range tests {
+		b, name, err := ToBytes(test, WithSerialisationApproach(approach), WithFlateThreshold(-1))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error packing %T: %v", i, test, err)
+		}
+		if name != JSONApproachName {
+			t.Fatalf("(%d) Expected %s, got: %s", i, JSONApproachName, name)
+		}
+		if !json.Valid(b[1:]) {
+			t.Fatalf("(%d) Expected valid JSON, got: %s", i, b[1:])
+		}
+
+		v, err := FromBytesAuto(b, name)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error unpacking %T from %s: %v", i, test, b[1:], err)
+		}
+
+		expected := test
+		if c, ok := test.(testUnregisteredCount); ok {
+			expected = uint32(c) // Unregistered named types are deserialised as their underlying type
+		}
+
+		switch x := expected.(type) {
+		case float64:
+			if math.Float64bits(x) != math.Float64bits(v.(float64)) {
+				t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+			}
+		case []float64:
+			if math.Float64bits(x[0]) != math.Float64bits(v.([]float64)[0]) || x[1] != v.([]float64)[1] {
+				t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+			}
+		case time.Time:
+			if !x.Equal(v.(time.Time)) {
+				t.Fatalf("(%d) Data mismatch: expected %v, got: %v", i, x, v)
+			}
+			if _, offset := x.Zone(); offset != func() int { _, o := v.(time.Time).Zone(); return o }() {
+				t.Fatalf("(%d) Offset mismatch: expected %v, got: %v", i, x, v)
+			}
+		case testClick, []testEvent, testEnvelope:
+			// time.Time values are compared by their encoding, as their locations differ
+			if reflect.TypeOf(v) != reflect.TypeOf(x) {
+				t.Fatalf("(%d) Type mismatch: expected %T, got: %T", i, x, v)
+			}
+			vb, _ := approach.Pack(v)
+			xb, _ := approach.Pack(x)
+			if !bytes.Equal(vb, xb) {
+				t.Fatalf("(%d) Data mismatch: expected %s, got: %s", i, xb, vb)
+			}
+		default:
+			if !reflect.DeepEqual(v, expected) {
+				t.Fatalf("(%d) Data mismatch: expected %#v, got: %#v", i, expected, v)
+			}
+		}
+	}
+
+	v, err := approach.Unpack([]byte("null"))
+	if err != nil || v != nil {
+		t.Fatalf("Expected nil, got: %v, %v", v, err)
+	}
+	if b, _ := approach.Pack(nil); string(b) != "null" {
+		t.Fatalf("Expected null, got: %s", b)
+	}
+}
+
+func TestJSONApproachFormat(t *testing.T) {
+
+	approach := NewJSONApproach()
+
+	tests := []struct {
+		V        any
+		Expected string
+	}{
+		{int8(1), `{"type":"int8","value":1}`},
+		{int64(1), `{"type":"int64","value":"1"}`},
+		{1.0, `{"type":"float64","value":1}`},
+		{math.NaN(), `{"type":"float64","value":"NaN"}`},
+		{[]byte("abc"), `{"type":"[]byte","value":"YWJj"}`},
+		{time.Date(2024, 2, 29, 12, 30, 0, 5, time.UTC), `{"type":"time.Time","value":"2024-02-29T12:30:00.000000005Z"}`},
+		{90 * time.Minute, `{"type":"time.Duration","value":"1h30m0s"}`},
+		{ptrMD(int16(2)), `{"type":"*int16","value":2}`},
+		{map[string]int8{"b": 2, "a": 1}, `{"type":"map[string]int8","value":{"a":1,"b":2}}`},
+		{map[int8]string{2: "b", 1: "a"}, `{"type":"map[int8]string","value":[[1,"a"],[2,"b"]]}`},
+		{[]any{int8(1), nil}, `{"type":"[]any","value":[{"type":"int8","value":1},null]}`},
+	}
+
+	for i, test := range tests {
+		b, err := approach.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if string(b) != test.Expected {
+			t.Fatalf("(%d) Format mismatch: expected %s, got: %s", i, test.Expected, b)
+		}
+	}
+
+	// Times with offsets that include seconds are serialised in UTC
+	lmt := time.Date(2024, 2, 29, 12, 30, 0, 0, time.FixedZone("LMT", -(4*60*60+56*60+2)))
+	b, err := approach.Pack(lmt)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := approach.Unpack(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !v.(time.Time).Equal(lmt) {
+		t.Fatalf("Data mismatch: expected %v, got: %v", lmt, v)
+	}
+}
+
+func TestJSONApproachErrors(t *testing.T) {
+
+	approach := NewJSONApproach()
+
+	type testUnregistered struct{ A int8 }
+	node := &testNode{Name: "a"}
+	node.Next = node
+
+	for i, test := range []struct {
+		V   any
+		Err error
+	}{
+		{1, ErrJSONTypeNotSerialisable},
+		{testUnregistered{}, ErrJSONTypeNotSerialisable},
+		{"\xff", ErrJSONInvalidUTF8},
+		{node, ErrJSONMaxNestingExceeded},
+	} {
+		if _, err := approach.Pack(test.V); err != test.Err {
+			t.Fatalf("(%d) Expected %v, got: %v", i, test.Err, err)
+		}
+		if approach.IsSerialisable(test.V) {
+			t.Fatalf("(%d) Expected %T to not be serialisable", i, test.V)
+		}
+	}
+
+	for i, test := range []struct {
+		Data string
+		Err  error
+	}{
+		{``, ErrJSONInvalidData},
+		{`{"type":"int8","value":1} 2`, ErrJSONInvalidData},
+		{`[1]`, ErrJSONInvalidData},
+		{`{"value":1}`, ErrJSONInvalidData},
+		{`{"type":"int8","value":300}`, ErrJSONInvalidData},
+		{`{"type":"int8","value":"x"}`, ErrJSONInvalidData},
+		{`{"type":"int64","value":true}`, ErrJSONInvalidData},
+		{`{"type":"[]byte","value":"!"}`, ErrJSONInvalidData},
+		{`{"type":"map[int8","value":[]}`, ErrJSONInvalidData},
+		{`{"type":"map[int8]int8","value":[[1]]}`, ErrJSONInvalidData},
+		{`{"type":"testUnknown","value":1}`, ErrUnknownTypeName},
+		{`{"type":"#999","value":""}`, ErrJSONInvalidData},
+		{`{"type":"[]any","value":[{"type":"int8"}]}`, ErrJSONInvalidData},
+	} {
+		if _, err := approach.Unpack([]byte(test.Data)); err != test.Err {
+			t.Fatalf("(%d) Expected %v, got: %v", i, test.Err, err)
+		}
+	}
+
+	// Values must be assignable to the interface type
+	if err := Register("testEvent", (*testEvent)(nil)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := approach.Unpack([]byte(`{"type":"[]testEvent","value":[{"type":"int8","value":1}]}`)); err != ErrJSONInvalidData {
+		t.Fatalf("Expected ErrJSONInvalidData, got: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package serialise
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// appendBitsMD appends the count of the bools followed by the bools packed 8 per byte,
+// with the first bool held in the least significant bit of the first byte
+func appendBitsMD(b []byte, data []bool) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+
+	n := len(b)
+	size := (len(data) + 7) / 8
+	b = slices.Grow(b, size)[:n+size]
+	packed := b[n:]
+	clear(packed)
+	for i, v := range data {
+		if v {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return b
+}
+
+// readBitsMD reads a []bool created by appendBitsMD
+func readBitsMD(data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+	if size > 8*len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := make([]bool, size)
+	for i := range v {
+		v[i] = data[i/8]&(1<<(i%8)) != 0
+	}
+	return v, nil
+}
+
+// appendBitSetMD appends a BitSet in the same form as appendBitsMD, which
+// is the little endian representation of the words of the BitSet
+func appendBitSetMD(b []byte, bs *BitSet) []byte {
+	b = binary.AppendUvarint(b, uint64(bs.n))
+
+	size := (bs.n + 7) / 8
+	if nativeLittleEndianMD {
+		return append(b, bytesOfMD(bs.words)[:size]...)
+	}
+
+	n := len(b)
+	for _, w := range bs.words {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b[:n+size]
+}
+
+// readBitSetMD reads a BitSet created by appendBitSetMD or appendBitsMD, copying
+// the bits directly into the BitSet
+func readBitSetMD(data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+	if size > 8*len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	bs := NewBitSet(size)
+	packed := data[:(size+7)/8]
+	if nativeLittleEndianMD {
+		copy(bytesOfMD(bs.words), packed)
+	} else {
+		for i, p := range packed {
+			bs.words[i/8] |= uint64(p) << (8 * (i % 8))
+		}
+	}
+
+	// Bits beyond the length of the BitSet are ignored
+	if size%64 != 0 {
+		bs.words[len(bs.words)-1] &= 1<<(size%64) - 1
+	}
+	return bs, nil
+}
+
+// unpackBoolsAsBitSetMD deserialises a []bool as a BitSet, reading
+// the bits directly if the bools were bit-packed
+func (m *minData) unpackBoolsAsBitSetMD(data []byte) (output any, e error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = nil
+			e = ErrUnexpectedDeserialisationError
+		}
+	}()
+
+	if m.version >= V4 && sliceEncodingMD(data[0]) == bitPackedEncodingMD {
+		return readBitSetMD(data[1:])
+	}
+
+	v, err := unpackBoolSliceMD(data, m.version)
+	if err != nil {
+		return nil, err
+	}
+	return NewBitSetFromBools(v.([]bool)), nil
+}
@@ -0,0 +1,155 @@
+package serialise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMinDataV4(t *testing.T) {
+
+	flags := make([]bool, 1_000_000)
+	for i := range flags {
+		flags[i] = (i*7919)%13 < 5
+	}
+	sparse := make([]bool, 100_000)
+	sparse[500] = true
+
+	v3 := NewMinDataApproachWithVersion(V3)
+	v4 := NewMinDataApproachWithVersion(V4)
+	if v4.Name() != "MD4" {
+		t.Fatalf("Unexpected name: %s", v4.Name())
+	}
+
+	tests := []struct {
+		V        []bool
+		Encoding sliceEncodingMD
+	}{
+		{flags, bitPackedEncodingMD},
+		{sparse, runLengthEncodingMD},
+		{[]bool{true, false, true}, bitPackedEncodingMD},
+		{[]bool{}, bitPackedEncodingMD},
+	}
+
+	for i, test := range tests {
+		b, err := v4.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if enc := sliceEncodingMD(b[2]); enc != test.Encoding {
+			t.Fatalf("(%d) Unexpected encoding: expected: %d, got: %d", i, test.Encoding, enc)
+		}
+
+		v, err := v4.Unpack(b)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, test.V) {
+			t.Fatalf("(%d) Data mismatch", i)
+		}
+	}
+
+	// Bit-packing uses a bit per bool
+	b, err := v4.Pack(flags)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(b) > len(flags)/8+10 {
+		t.Fatalf("Expected bit-packed encoding, got: %d bytes", len(b))
+	}
+
+	// A *BitSet is serialised in the same form, and deserialised as a *BitSet
+	bs := NewBitSetFromBools(flags)
+	bb, err := v4.Pack(bs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(bb[2:], b[2:]) {
+		t.Fatal("Expected *BitSet and []bool to share the bit-packed form")
+	}
+	v, err := v4.Unpack(bb)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(v, bs) {
+		t.Fatal("Data mismatch for *BitSet")
+	}
+
+	for _, test := range []any{(*BitSet)(nil), NewBitSet(0), NewBitSet(65), []*BitSet{NewBitSet(3), nil}} {
+		b, err := v4.Pack(test)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", test, err)
+		}
+		v, err := v4.Unpack(b)
+		if err != nil {
+			t.Fatalf("Unexpected error for %v: %v", test, err)
+		}
+		if !reflect.DeepEqual(v, test) {
+			t.Fatalf("Data mismatch: expected: %v, got: %v", test, v)
+		}
+	}
+
+	// Earlier versions do not support *BitSet
+	if _, err := v3.Pack(bs); err != ErrMinDataTypeNotSerialisable {
+		t.Fatalf("Expected ErrMinDataTypeNotSerialisable, got: %v", err)
+	}
+	if v3.IsSerialisable(bs) {
+		t.Fatal("Expected *BitSet to not be serialisable by V3")
+	}
+}
+
+func TestBoolsAsBitSet(t *testing.T) {
+
+	flags := []bool{true, false, false, true, true, false, true, false, true}
+	expected := NewBitSetFromBools(flags)
+
+	for _, version := range []MinDataVersion{V1, V2, V3, V4} {
+		approach := NewMinDataApproachWithVersion(version)
+
+		b, _, err := ToBytes(flags, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", version, err)
+		}
+
+		v, err := FromBytes(b, approach, WithBoolsAsBitSet())
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", version, err)
+		}
+		if !reflect.DeepEqual(v, expected) {
+			t.Fatalf("(%d) Data mismatch: expected: %v, got: %v", version, expected, v)
+		}
+
+		// Nested []bool values are unaffected
+		b, _, err = ToBytes(map[string][]bool{"a": flags}, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", version, err)
+		}
+		v, err = FromBytes(b, approach, WithBoolsAsBitSet())
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", version, err)
+		}
+		if !reflect.DeepEqual(v, map[string][]bool{"a": flags}) {
+			t.Fatalf("(%d) Data mismatch for nested []bool", version)
+		}
+	}
+
+	// Bits beyond the length of the BitSet are ignored
+	header := versionHeaderMD | byte(V4)
+	approach := NewMinDataApproachWithVersion(V4)
+	v, err := FromBytes([]byte{0, header, byte(BoolSliceType), byte(bitPackedEncodingMD), 3, 0xff}, approach, WithBoolsAsBitSet())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bs := v.(*BitSet); bs.Len() != 3 || bs.Count() != 3 {
+		t.Fatalf("Unexpected BitSet: %v", bs.Bools())
+	}
+
+	for i, test := range [][]byte{
+		{0, header, byte(BoolSliceType)},
+		{0, header, byte(BoolSliceType), byte(bitPackedEncodingMD), 9, 0xff},
+		{0, header, byte(BitSetType), byte(plainEncodingMD), 1, 1},
+	} {
+		if _, err := FromBytes(test, approach, WithBoolsAsBitSet()); err == nil {
+			t.Fatalf("(%d) Expected error for invalid data", i)
+		}
+	}
+}
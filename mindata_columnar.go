@@ -0,0 +1,464 @@
+package serialise
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"unsafe"
+)
+
+// sliceEncodingMD identifies how the values of a slice are encoded.  The encoding of numeric
+// and bool slices from V3 onwards, and of string slices from V5 onwards, is recorded after the
+// TypeID of the slice, and the smallest of the encodings that are applicable to the slice is selected.
+type sliceEncodingMD byte
+
+const (
+	plainEncodingMD      sliceEncodingMD = iota // Count followed by each value
+	deltaEncodingMD                             // Count, first value and first delta, followed by each delta-of-delta
+	runLengthEncodingMD                         // Count followed by (run length, value) pairs
+	xorEncodingMD                               // Count followed by the Gorilla XOR encoding of float64 values
+	bitPackedEncodingMD                         // Count followed by bools packed 8 per byte, from V4
+	dictionaryEncodingMD                        // Count, the distinct values, and then the index of each value, from V5
+)
+
+// keepSmallerMD retains whichever of the encodings b[start:n] and b[n:] is shorter,
+// returning b truncated to the end of the retained encoding
+func keepSmallerMD(b []byte, start, n int) []byte {
+	if len(b)-n < n-start {
+		return b[:start+copy(b[start:], b[n:])]
+	}
+	return b[:n]
+}
+
+// viewMD returns the memory of data as a slice of U, which must be the same size as T
+func viewMD[U, T any](data []T) []U {
+	return unsafe.Slice((*U)(unsafe.Pointer(unsafe.SliceData(data))), len(data))
+}
+
+// countRunsMD returns the number of runs of equal values within data
+func countRunsMD[T comparable](data []T) int {
+	runs := 0
+	for i := range data {
+		if i == 0 || data[i] != data[i-1] {
+			runs++
+		}
+	}
+	return runs
+}
+
+// worthRunLengthMD is true if there are sufficient repeated values that run
+// length encoding is likely to be smaller than the other encodings
+func worthRunLengthMD[T comparable](data []T) bool {
+	return len(data) > 1 && countRunsMD(data) <= len(data)/2
+}
+
+// appendRunsMD appends the count of the values, followed by each run of equal values
+// as the length of the run and the value
+func appendRunsMD[T comparable](b []byte, data []T, appendValue func([]byte, T) []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	for i := 0; i < len(data); {
+		j := i + 1
+		for j < len(data) && data[j] == data[i] {
+			j++
+		}
+		b = binary.AppendUvarint(b, uint64(j-i))
+		b = appendValue(b, data[i])
+		i = j
+	}
+	return b
+}
+
+// readRunsMD reads values created by appendRunsMD.  readValue returns the
+// value and the number of bytes read, which is not positive if the data is invalid.
+// The count is not trusted to size the slice, which grows as each run is read.
+func readRunsMD[T any](data []byte, readValue func([]byte) (T, int)) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+
+	v := make([]T, 0, min(size, len(data)))
+	for len(v) < size {
+		run, n := binary.Uvarint(data)
+		if n <= 0 || run == 0 || run > uint64(size-len(v)) {
+			return nil, ErrUnexpectedDeserialisationError
+		}
+		data = data[n:]
+
+		value, n := readValue(data)
+		if n <= 0 {
+			return nil, ErrUnexpectedDeserialisationError
+		}
+		data = data[n:]
+
+		for range int(run) {
+			v = append(v, value)
+		}
+	}
+	return v, nil
+}
+
+// toBitsMD returns the two's complement representation of v
+func toBitsMD[T varintMD](v T) uint64 {
+	if ^T(0) < 0 {
+		return uint64(int64(v))
+	}
+	return uint64(v)
+}
+
+// fromBitsMD reverses toBitsMD, returning false if the value overflows T
+func fromBitsMD[T varintMD](u uint64) (T, bool) {
+	if ^T(0) < 0 {
+		return T(int64(u)), int64(T(int64(u))) == int64(u)
+	}
+	return T(u), uint64(T(u)) == u
+}
+
+// appendDeltaMD appends the count of the values, followed by the first value, the
+// difference between the first and second values, and then the change in the difference
+// between each subsequent pair of values, so that regularly spaced values such as
+// timestamps are recorded as a sequence of zeros.  Differences wrap on overflow.
+func appendDeltaMD[T varintMD](b []byte, data []T) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+
+	var prev, delta uint64
+	for i, v := range data {
+		u := toBitsMD(v)
+		if i == 0 {
+			b = appendVarintValueMD(b, v)
+		} else {
+			d := u - prev
+			b = binary.AppendVarint(b, int64(d-delta))
+			delta = d
+		}
+		prev = u
+	}
+	return b
+}
+
+// readDeltaMD reads values created by appendDeltaMD
+func readDeltaMD[T varintMD](data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+	if size > len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := make([]T, size)
+	var prev, delta uint64
+	for i := range v {
+		if i == 0 {
+			if v[0], n = readVarintValueMD[T](data); n <= 0 {
+				return nil, ErrUnexpectedDeserialisationError
+			}
+			prev = toBitsMD(v[0])
+		} else {
+			var dod int64
+			if dod, n = binary.Varint(data); n <= 0 {
+				return nil, ErrUnexpectedDeserialisationError
+			}
+			delta += uint64(dod)
+			prev += delta
+
+			var ok bool
+			if v[i], ok = fromBitsMD[T](prev); !ok {
+				return nil, ErrUnexpectedDeserialisationError
+			}
+		}
+		data = data[n:]
+	}
+	return v, nil
+}
+
+// appendIntColumnMD appends the smallest of the plain, delta and run length encodings of the integers
+func appendIntColumnMD[T varintMD](b []byte, data []T) []byte {
+	start := len(b)
+	b = appendVarintsMD(append(b, byte(plainEncodingMD)), data)
+
+	if len(data) > 2 {
+		n := len(b)
+		b = keepSmallerMD(appendDeltaMD(append(b, byte(deltaEncodingMD)), data), start, n)
+	}
+	if worthRunLengthMD(data) {
+		n := len(b)
+		b = keepSmallerMD(appendRunsMD(append(b, byte(runLengthEncodingMD)), data, appendVarintValueMD[T]), start, n)
+	}
+	return b
+}
+
+// unpackIntColumnMD deserialises integers created by appendIntColumnMD
+func unpackIntColumnMD[T varintMD](data []byte) (any, error) {
+	switch sliceEncodingMD(data[0]) {
+	case plainEncodingMD:
+		return readVarintsMD[T](data[1:])
+	case deltaEncodingMD:
+		return readDeltaMD[T](data[1:])
+	case runLengthEncodingMD:
+		return readRunsMD(data[1:], readVarintValueMD[T])
+	default:
+		return nil, ErrUnexpectedDeserialisationError
+	}
+}
+
+// appendFloat32SliceMD appends a slice of float32, using the encoding of the version
+func appendFloat32SliceMD(b []byte, t TypeID, data []float32, version MinDataVersion) []byte {
+	if version < V3 {
+		return appendSimpleSliceMD(b, t, data, version >= V2)
+	}
+
+	b = append(b, byte(t))
+	start := len(b)
+	b = appendRawSliceMD(append(b, byte(plainEncodingMD)), data)
+
+	// Values are compared using their bits, so that NaN values and signed zeros are retained
+	u := viewMD[uint32](data)
+	if worthRunLengthMD(u) {
+		n := len(b)
+		b = keepSmallerMD(appendRunsMD(append(b, byte(runLengthEncodingMD)), u, appendFixedMD[uint32]), start, n)
+	}
+	return b
+}
+
+// unpackFloat32SliceMD deserialises a slice created by appendFloat32SliceMD
+func unpackFloat32SliceMD(data []byte, version MinDataVersion) (any, error) {
+	if version < V3 {
+		return readSimpleSliceMD[float32](data, 4, version >= V2)
+	}
+
+	switch sliceEncodingMD(data[0]) {
+	case plainEncodingMD:
+		return readSimpleSliceMD[float32](data[1:], 4, true)
+	case runLengthEncodingMD:
+		return readRunsMD(data[1:], func(data []byte) (float32, int) {
+			if len(data) < 4 {
+				return 0, 0
+			}
+			return math.Float32frombits(readFixedMD[uint32](data)), 4
+		})
+	default:
+		return nil, ErrUnexpectedDeserialisationError
+	}
+}
+
+// appendFloat64SliceMD appends a slice of float64, using the encoding of the version
+func appendFloat64SliceMD(b []byte, t TypeID, data []float64, version MinDataVersion) []byte {
+	if version < V3 {
+		return appendSimpleSliceMD(b, t, data, version >= V2)
+	}
+
+	b = append(b, byte(t))
+	start := len(b)
+	b = appendRawSliceMD(append(b, byte(plainEncodingMD)), data)
+
+	// Values are compared using their bits, so that NaN values and signed zeros are retained
+	u := viewMD[uint64](data)
+	if len(data) > 1 {
+		n := len(b)
+		b = keepSmallerMD(appendXorMD(append(b, byte(xorEncodingMD)), u), start, n)
+	}
+	if worthRunLengthMD(u) {
+		n := len(b)
+		b = keepSmallerMD(appendRunsMD(append(b, byte(runLengthEncodingMD)), u, appendFixedMD[uint64]), start, n)
+	}
+	return b
+}
+
+// unpackFloat64SliceMD deserialises a slice created by appendFloat64SliceMD
+func unpackFloat64SliceMD(data []byte, version MinDataVersion) (any, error) {
+	if version < V3 {
+		return readSimpleSliceMD[float64](data, 8, version >= V2)
+	}
+
+	switch sliceEncodingMD(data[0]) {
+	case plainEncodingMD:
+		return readSimpleSliceMD[float64](data[1:], 8, true)
+	case xorEncodingMD:
+		return readXorMD(data[1:])
+	case runLengthEncodingMD:
+		return readRunsMD(data[1:], func(data []byte) (float64, int) {
+			if len(data) < 8 {
+				return 0, 0
+			}
+			return math.Float64frombits(readFixedMD[uint64](data)), 8
+		})
+	default:
+		return nil, ErrUnexpectedDeserialisationError
+	}
+}
+
+// appendBoolSliceMD appends a slice of bool, using the encoding of the version
+func appendBoolSliceMD(b []byte, t TypeID, data []bool, version MinDataVersion) []byte {
+	if version < V3 {
+		return appendSimpleSliceMD(b, t, data, version >= V2)
+	}
+
+	b = append(b, byte(t))
+	start := len(b)
+	if version >= V4 {
+		b = appendBitsMD(append(b, byte(bitPackedEncodingMD)), data)
+	} else {
+		b = appendRawSliceMD(append(b, byte(plainEncodingMD)), data)
+	}
+
+	if worthRunLengthMD(data) {
+		n := len(b)
+		b = keepSmallerMD(appendRunsMD(append(b, byte(runLengthEncodingMD)), data, appendBoolMD), start, n)
+	}
+	return b
+}
+
+// unpackBoolSliceMD deserialises a slice created by appendBoolSliceMD
+func unpackBoolSliceMD(data []byte, version MinDataVersion) (any, error) {
+	if version < V3 {
+		return readSimpleSliceMD[bool](data, 1, version >= V2)
+	}
+
+	switch sliceEncodingMD(data[0]) {
+	case plainEncodingMD:
+		return readSimpleSliceMD[bool](data[1:], 1, true)
+	case bitPackedEncodingMD:
+		return readBitsMD(data[1:])
+	case runLengthEncodingMD:
+		return readRunsMD(data[1:], func(data []byte) (bool, int) {
+			if len(data) < 1 {
+				return false, 0
+			}
+			return data[0] != 0, 1
+		})
+	default:
+		return nil, ErrUnexpectedDeserialisationError
+	}
+}
+
+// appendRawSliceMD appends the count of the values as a varint, followed by
+// the little endian representation of each value
+func appendRawSliceMD[T any](b []byte, data []T) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+
+	n := len(b)
+	b = append(b, bytesOfMD(data)...)
+	if !nativeLittleEndianMD {
+		swapEachMD(b[n:], int(unsafe.Sizeof(*new(T))))
+	}
+	return b
+}
+
+// bitWriterMD appends values to a byte slice as a stream of bits, most significant bit first
+type bitWriterMD struct {
+	b    []byte
+	free uint // The number of unused bits in the last byte
+}
+
+// write appends the lowest nbits bits of v
+func (w *bitWriterMD) write(v uint64, nbits uint) {
+	for nbits > 0 {
+		if w.free == 0 {
+			w.b = append(w.b, 0)
+			w.free = 8
+		}
+		k := min(nbits, w.free)
+		w.b[len(w.b)-1] |= byte((v>>(nbits-k))&(1<<k-1)) << (w.free - k)
+		w.free -= k
+		nbits -= k
+	}
+}
+
+// bitReaderMD reads values written by bitWriterMD.  Reading beyond
+// the end of the data panics, which is recovered by Unpack.
+type bitReaderMD struct {
+	data []byte
+	pos  uint // The number of bits read
+}
+
+// read returns the next nbits bits
+func (r *bitReaderMD) read(nbits uint) uint64 {
+	var v uint64
+	for nbits > 0 {
+		avail := 8 - r.pos%8
+		k := min(nbits, avail)
+		v = v<<k | uint64(r.data[r.pos/8]>>(avail-k))&(1<<k-1)
+		r.pos += k
+		nbits -= k
+	}
+	return v
+}
+
+// appendXorMD appends the count of the values followed by the Gorilla XOR encoding of the
+// values: the first value in full, and then each value as its XOR with the previous value.
+// An XOR of zero is recorded as a single bit; otherwise the meaningful bits of the XOR are
+// recorded, reusing the previous leading and trailing zero counts where these are sufficient.
+func appendXorMD(b []byte, data []uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	if len(data) == 0 {
+		return b
+	}
+
+	w := bitWriterMD{b: b}
+	w.write(data[0], 64)
+
+	var lead, trail uint
+	window := false
+	for i := 1; i < len(data); i++ {
+		x := data[i] ^ data[i-1]
+		if x == 0 {
+			w.write(0, 1)
+			continue
+		}
+		w.write(1, 1)
+
+		l := min(uint(bits.LeadingZeros64(x)), 31)
+		t := uint(bits.TrailingZeros64(x))
+		if window && l >= lead && t >= trail {
+			w.write(0, 1)
+			w.write(x>>trail, 64-lead-trail)
+			continue
+		}
+
+		lead, trail, window = l, t, true
+		w.write(1, 1)
+		w.write(uint64(lead), 5)
+		w.write(uint64(64-lead-trail-1), 6)
+		w.write(x>>trail, 64-lead-trail)
+	}
+	return w.b
+}
+
+// readXorMD reads float64 values created by appendXorMD
+func readXorMD(data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+	if size > 8*len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := make([]float64, size)
+	if size == 0 {
+		return v, nil
+	}
+	u := viewMD[uint64](v)
+
+	r := bitReaderMD{data: data}
+	u[0] = r.read(64)
+
+	var lead, trail uint
+	window := false
+	for i := 1; i < size; i++ {
+		if r.read(1) == 0 {
+			u[i] = u[i-1]
+			continue
+		}
+
+		if r.read(1) == 1 {
+			lead = uint(r.read(5))
+			sig := uint(r.read(6)) + 1
+			if lead+sig > 64 {
+				return nil, ErrUnexpectedDeserialisationError
+			}
+			trail = 64 - lead - sig
+			window = true
+		} else if !window {
+			return nil, ErrUnexpectedDeserialisationError
+		}
+
+		u[i] = u[i-1] ^ r.read(64-lead-trail)<<trail
+	}
+	return v, nil
+}
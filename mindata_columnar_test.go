@@ -0,0 +1,202 @@
+package serialise
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMinDataV3(t *testing.T) {
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	timestamps := make([]int64, 1000)
+	readings := make([]float64, 1000)
+	flags := make([]bool, 1000)
+	statuses := make([]uint16, 1000)
+	durations := make([]time.Duration, 1000)
+	repeated := make([]float64, 1000)
+	random := make([]int32, 1000)
+	rnd := rand.New(rand.NewSource(42))
+	for i := range timestamps {
+		timestamps[i] = start + int64(i)*int64(time.Second)
+		readings[i] = 20 + float64(i/10)*0.5
+		repeated[i] = 1.5
+		flags[i] = i >= 500
+		statuses[i] = uint16(i / 250)
+		durations[i] = time.Duration(i) * time.Millisecond
+		random[i] = rnd.Int31() - math.MaxInt32/2
+	}
+
+	type testData struct {
+		V        any
+		Encoding sliceEncodingMD
+	}
+
+	tests := []testData{
+		{timestamps, deltaEncodingMD},
+		{durations, deltaEncodingMD},
+		{[]int64{math.MaxInt64, math.MinInt64, math.MaxInt64, 0}, deltaEncodingMD},
+		{[]uint64{math.MaxUint64, 0, math.MaxUint64, 1, 2, 3}, deltaEncodingMD},
+		{statuses, runLengthEncodingMD},
+		{random, plainEncodingMD},
+		{[]int16{}, plainEncodingMD},
+		{[]int32{7}, plainEncodingMD},
+		{readings, xorEncodingMD},
+		{repeated, runLengthEncodingMD},
+		{[]float64{20.1, 20.2, 20.2, 20.3, 20.1, 20.4, 20.4, 20.5}, xorEncodingMD},
+		{[]float64{math.NaN(), math.Copysign(0, -1), 0, math.Inf(-1)}, plainEncodingMD},
+		{[]float64{}, plainEncodingMD},
+		{[]float32{1.5, 1.5, 1.5, 1.5, float32(math.Copysign(0, -1)), 0}, runLengthEncodingMD},
+		{[]float32{1.5, 2.5}, plainEncodingMD},
+		{flags, runLengthEncodingMD},
+		{[]bool{true, false, true}, plainEncodingMD},
+	}
+
+	v2 := NewMinDataApproachWithVersion(V2)
+	v3 := NewMinDataApproachWithVersion(V3)
+	if v3.Name() != "MD3" {
+		t.Fatalf("Unexpected name: %s", v3.Name())
+	}
+
+	for i, test := range tests {
+		b, err := v3.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if enc := sliceEncodingMD(b[2]); enc != test.Encoding {
+			t.Fatalf("(%d) Unexpected encoding for %T: expected: %d, got: %d", i, test.V, test.Encoding, enc)
+		}
+
+		b2, err := v2.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if len(b) > len(b2)+1 {
+			t.Fatalf("(%d) Expected V3 to be no larger than V2 plus the encoding: V2: %d, V3: %d", i, len(b2), len(b))
+		}
+
+		v, err := v3.Unpack(b)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+
+		// Floats are compared using their bits, so that NaN and signed zeros are checked
+		switch f := test.V.(type) {
+		case []float64:
+			if !reflect.DeepEqual(viewMD[uint64](v.([]float64)), viewMD[uint64](f)) {
+				t.Fatalf("(%d) Data mismatch: expected: %v, got: %v", i, f, v)
+			}
+		case []float32:
+			if !reflect.DeepEqual(viewMD[uint32](v.([]float32)), viewMD[uint32](f)) {
+				t.Fatalf("(%d) Data mismatch: expected: %v, got: %v", i, f, v)
+			}
+		default:
+			if !reflect.DeepEqual(v, test.V) {
+				t.Fatalf("(%d) Data mismatch: expected: %v, got: %v", i, test.V, v)
+			}
+		}
+	}
+
+	// Regularly spaced timestamps are reduced to little more than a byte per value
+	b, err := v3.Pack(timestamps)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(b) > len(timestamps)+20 {
+		t.Fatalf("Expected delta encoding to be compact, got: %d bytes", len(b))
+	}
+
+	// Slices nested within other types also use the columnar encodings
+	m := map[string][]int64{"ts": timestamps}
+	b, err = v3.Pack(m)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := v3.Unpack(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(v, m) {
+		t.Fatal("Data mismatch for nested slice")
+	}
+}
+
+func TestMinDataV3InvalidData(t *testing.T) {
+
+	approach := NewMinDataApproachWithVersion(V3)
+	header := versionHeaderMD | byte(V3)
+
+	tests := [][]byte{
+		{header, byte(Int64SliceType), 9, 0},
+		{header, byte(Int64SliceType), byte(xorEncodingMD), 0},
+		{header, byte(Int64SliceType), byte(runLengthEncodingMD), 3, 4, 2},
+		{header, byte(Int64SliceType), byte(runLengthEncodingMD), 3, 0, 2},
+		{header, byte(Int16SliceType), byte(deltaEncodingMD), 2, 0, 0xfe, 0xff, 0x7f},
+		{header, byte(Float64SliceType), byte(xorEncodingMD), 2, 0, 0, 0, 0, 0, 0, 0, 0, 0x80},
+		{header, byte(Float64SliceType), byte(xorEncodingMD), 2, 0, 0, 0, 0, 0, 0, 0, 0},
+		{header, byte(Float32SliceType), byte(deltaEncodingMD), 0},
+		{header, byte(BoolSliceType), byte(runLengthEncodingMD), 2, 2},
+		// A corrupt count of 1<<33 values must not be allocated before the runs are read
+		{header, byte(Int64SliceType), byte(runLengthEncodingMD), 0x80, 0x80, 0x80, 0x80, 0x20, 1, 2},
+	}
+
+	for i, test := range tests {
+		if _, err := approach.Unpack(test); err != ErrUnexpectedDeserialisationError {
+			t.Fatalf("(%d) Expected ErrUnexpectedDeserialisationError, got: %v", i, err)
+		}
+	}
+}
+
+func TestBitWriterMD(t *testing.T) {
+
+	type value struct {
+		V     uint64
+		NBits uint
+	}
+
+	values := []value{{1, 1}, {0, 1}, {0x1f, 5}, {math.MaxUint64, 64}, {0x2a, 6}, {0, 3}, {0x123456789, 37}}
+
+	w := bitWriterMD{}
+	for _, v := range values {
+		w.write(v.V, v.NBits)
+	}
+
+	r := bitReaderMD{data: w.b}
+	for i, v := range values {
+		if got := r.read(v.NBits); got != v.V {
+			t.Fatalf("(%d) Mismatch: expected: %x, got: %x", i, v.V, got)
+		}
+	}
+}
+
+func TestXorMD(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(42))
+	for range 100 {
+		data := make([]float64, rnd.Intn(50))
+		for i := range data {
+			switch rnd.Intn(4) {
+			case 0:
+				data[i] = rnd.NormFloat64()
+			case 1:
+				data[i] = float64(rnd.Intn(10))
+			case 2:
+				data[i] = math.Float64frombits(rnd.Uint64())
+			default:
+				if i > 0 {
+					data[i] = data[i-1]
+				}
+			}
+		}
+
+		v, err := readXorMD(appendXorMD(nil, viewMD[uint64](data)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(viewMD[uint64](v.([]float64)), viewMD[uint64](data)) {
+			t.Fatalf("Data mismatch: expected: %v, got: %v", data, v)
+		}
+	}
+}
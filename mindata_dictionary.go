@@ -0,0 +1,109 @@
+package serialise
+
+import "encoding/binary"
+
+// appendStringSliceMD appends a slice of strings, using the encoding of the version
+func appendStringSliceMD(b []byte, t TypeID, data []string, version MinDataVersion) []byte {
+	if version < V5 {
+		return appendByteSliceSliceMD(b, t, data, version >= V2)
+	}
+
+	b = append(b, byte(t))
+	start := len(b)
+	b = appendLenPrefixedMD(append(b, byte(plainEncodingMD)), data, true)
+
+	if len(data) > 1 {
+		n := len(b)
+		if db, ok := appendDictionaryMD(append(b, byte(dictionaryEncodingMD)), data); ok {
+			return keepSmallerMD(db, start, n)
+		}
+		b = b[:n]
+	}
+	return b
+}
+
+// appendDictionaryMD appends the count of the strings, followed by the distinct strings
+// and then the index of each string within the distinct strings.  Returns false
+// if more than half of the strings are distinct, as the encoding is unlikely to be smaller.
+func appendDictionaryMD(b []byte, data []string) ([]byte, bool) {
+	index := map[string]int{}
+	dict := []string{}
+	for _, s := range data {
+		if _, ok := index[s]; !ok {
+			if len(dict) >= len(data)/2 {
+				return b, false
+			}
+			index[s] = len(dict)
+			dict = append(dict, s)
+		}
+	}
+
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	b = appendLenPrefixedMD(b, dict, true)
+	for _, s := range data {
+		b = binary.AppendUvarint(b, uint64(index[s]))
+	}
+	return b, true
+}
+
+// unpackStringSliceMD deserialises a slice created by appendStringSliceMD
+func (m *minData) unpackStringSliceMD(data []byte) (any, error) {
+	if m.version < V5 {
+		bss, err := unpackByteSliceSliceMD(data, m.compact())
+		if err != nil {
+			return nil, err
+		}
+		return m.stringsMD(bss), nil
+	}
+
+	switch sliceEncodingMD(data[0]) {
+	case plainEncodingMD:
+		bss, _, err := readLenPrefixedMD(data[1:], true)
+		if err != nil {
+			return nil, err
+		}
+		return m.stringsMD(bss), nil
+	case dictionaryEncodingMD:
+		return m.readDictionaryMD(data[1:])
+	default:
+		return nil, ErrUnexpectedDeserialisationError
+	}
+}
+
+// stringsMD returns the items as strings
+func (m *minData) stringsMD(bss [][]byte) []string {
+	ss := make([]string, len(bss))
+	for i := range bss {
+		ss[i] = m.stringMD(bss[i])
+	}
+	return ss
+}
+
+// readDictionaryMD reads strings created by appendDictionaryMD.  Each distinct string
+// is deserialised once, so that repeated strings share memory.
+func (m *minData) readDictionaryMD(data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+
+	bss, n, err := readLenPrefixedMD(data, true)
+	if err != nil {
+		return nil, err
+	}
+	data = data[n:]
+	if size > len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	dict := m.stringsMD(bss)
+
+	ss := make([]string, size)
+	for i := range ss {
+		idx, n := binary.Uvarint(data)
+		if n <= 0 || idx >= uint64(len(dict)) {
+			return nil, ErrUnexpectedDeserialisationError
+		}
+		ss[i] = dict[idx]
+		data = data[n:]
+	}
+	return ss, nil
+}
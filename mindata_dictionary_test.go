@@ -0,0 +1,139 @@
+package serialise
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestMinDataV5(t *testing.T) {
+
+	countries := make([]string, 1000)
+	unique := make([]string, 1000)
+	for i := range countries {
+		countries[i] = []string{"GB", "FR", "DE", "US"}[i%4]
+		unique[i] = fmt.Sprintf("id-%d", i)
+	}
+
+	v4 := NewMinDataApproachWithVersion(V4)
+	v5 := NewMinDataApproachWithVersion(V5)
+	if v5.Name() != "MD5" {
+		t.Fatalf("Unexpected name: %s", v5.Name())
+	}
+
+	tests := []struct {
+		V        []string
+		Encoding sliceEncodingMD
+	}{
+		{countries, dictionaryEncodingMD},
+		{unique, plainEncodingMD},
+		{[]string{"a", "a"}, plainEncodingMD},
+		{[]string{"active", "active", "active", ""}, dictionaryEncodingMD},
+		{[]string{}, plainEncodingMD},
+	}
+
+	for i, test := range tests {
+		b, err := v5.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if enc := sliceEncodingMD(b[2]); enc != test.Encoding {
+			t.Fatalf("(%d) Unexpected encoding: expected: %d, got: %d", i, test.Encoding, enc)
+		}
+
+		v, err := v5.Unpack(b)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, test.V) {
+			t.Fatalf("(%d) Data mismatch: expected: %v, got: %v", i, test.V, v)
+		}
+	}
+
+	b4, err := v4.Pack(countries)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b5, err := v5.Pack(countries)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(b5) > len(b4)/2 {
+		t.Fatalf("Expected dictionary encoding to be smaller: V4: %d, V5: %d", len(b4), len(b5))
+	}
+
+	// Repeated strings within a dictionary encoded slice share memory
+	v, err := v5.Unpack(b5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ss := v.([]string)
+	if unsafe.StringData(ss[0]) != unsafe.StringData(ss[4]) {
+		t.Fatal("Expected repeated strings to share memory")
+	}
+
+	invalid := [][]byte{
+		{versionHeaderMD | byte(V5), byte(StringSliceType), byte(deltaEncodingMD), 0},
+		{versionHeaderMD | byte(V5), byte(StringSliceType), byte(dictionaryEncodingMD), 2, 1, 1, 'a', 0, 1},
+		{versionHeaderMD | byte(V5), byte(StringSliceType), byte(dictionaryEncodingMD), 3, 1, 1, 'a', 0, 0},
+	}
+	for i, test := range invalid {
+		if _, err := v5.Unpack(test); err != ErrUnexpectedDeserialisationError {
+			t.Fatalf("(%d) Expected ErrUnexpectedDeserialisationError, got: %v", i, err)
+		}
+	}
+}
+
+func TestStringInterning(t *testing.T) {
+
+	type testData struct {
+		V   any
+		Get func(v any) string
+	}
+
+	tests := []testData{
+		{"GB", func(v any) string { return v.(string) }},
+		{[]string{"x", "GB"}, func(v any) string { return v.([]string)[1] }},
+		{map[string]int64{"GB": 1}, func(v any) string {
+			for k := range v.(map[string]int64) {
+				return k
+			}
+			return ""
+		}},
+	}
+
+	for _, version := range []MinDataVersion{V1, V5} {
+		approach := NewMinDataApproachWithVersion(version)
+		pool := NewInternPool(0)
+
+		var first *byte
+		for i, test := range tests {
+			b, _, err := ToBytes(test.V, WithSerialisationApproach(approach), WithFlateThreshold(-1))
+			if err != nil {
+				t.Fatalf("(%d/%d) Unexpected error: %v", version, i, err)
+			}
+
+			v, err := FromBytes(b, approach, WithStringInterning(pool), WithZeroCopy())
+			if err != nil {
+				t.Fatalf("(%d/%d) Unexpected error: %v", version, i, err)
+			}
+			if !reflect.DeepEqual(v, test.V) {
+				t.Fatalf("(%d/%d) Data mismatch: expected: %v, got: %v", version, i, test.V, v)
+			}
+
+			// Interned strings are shared across calls, and do not alias the data
+			s := test.Get(v)
+			if first == nil {
+				first = unsafe.StringData(s)
+			}
+			if unsafe.StringData(s) != first {
+				t.Fatalf("(%d/%d) Expected interned string to be shared", version, i)
+			}
+			clear(b)
+			if s != "GB" {
+				t.Fatalf("(%d/%d) Interned string aliases the data: %q", version, i, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package serialise
+
+import (
+	"errors"
+	"reflect"
+)
+
+// maxNestingMD limits the depth of nested values, so that cyclic references
+// that are not tracked raise an error rather than exhausting the stack
+const maxNestingMD = 1000
+
+// ErrMinDataMaxNestingExceeded is raised if values are nested beyond the supported depth,
+// which will be the case if a cyclic reference is serialised without reference tracking
+var ErrMinDataMaxNestingExceeded = errors.New("maximum nesting depth exceeded - use WithReferenceTracking() for cyclic references")
+
+// refKey identifies a pointer; the type is included as a struct and its
+// first field share the same address
+type refKey struct {
+	ptr uintptr
+	t   reflect.Type
+}
+
+// refStateMD holds the state of a single call to Pack or Unpack
+type refStateMD struct {
+	depth  int
+	seen   map[refKey]int
+	values []reflect.Value
+}
+
+// withRefs returns a copy of the approach holding new state, which is then
+// used for the duration of a single call to Pack or Unpack
+func (m *minData) withRefs() *minData {
+	mm := *m
+	mm.refs = &refStateMD{}
+	return &mm
+}
+
+func (r *refStateMD) enter() error {
+	r.depth++
+	if r.depth > maxNestingMD {
+		return ErrMinDataMaxNestingExceeded
+	}
+	return nil
+}
+
+func (r *refStateMD) leave() {
+	r.depth--
+}
+
+// appendRef appends a back-reference to b and returns true if the pointer has already been
+// serialised.  Otherwise the pointer is assigned the next reference id, which is appended
+// to b ahead of the pointer's serialised value.  b is unchanged if data is not a non-nil pointer.
+func (r *refStateMD) appendRef(b []byte, data any, compact bool) ([]byte, bool) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return b, false
+	}
+
+	if r.seen == nil {
+		r.seen = map[refKey]int{}
+	}
+
+	key := refKey{ptr: v.Pointer(), t: v.Type()}
+	if id, ok := r.seen[key]; ok {
+		return appendLenMD(append(b, byte(BackRefType)), id, compact), true
+	}
+
+	id := len(r.seen)
+	r.seen[key] = id
+	return appendLenMD(append(b, byte(RefType)), id, compact), false
+}
+
+// unpackRefMD deserialises a pointer that was assigned a reference id.
+// Pointers to composite types are recorded before their element is deserialised,
+// so that cyclic references back to the pointer can be resolved.
+func (m *minData) unpackRefMD(data []byte) (any, error) {
+	id, n := readLenMD(data, m.compact())
+	if id != len(m.refs.values) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	data = data[n:]
+
+	if TypeID(data[0]) == PointerType {
+		p, data, err := m.newPointerMD(data[1:])
+		if err != nil {
+			return nil, err
+		}
+		m.refs.values = append(m.refs.values, p)
+
+		if _, err = m.unpackItemMD(data, p.Elem()); err != nil {
+			return nil, err
+		}
+		return p.Interface(), nil
+	}
+
+	m.refs.values = append(m.refs.values, reflect.Value{})
+
+	v, err := m.unpackMD(data)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(v)
+	if v == nil || rv.Kind() != reflect.Pointer {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	m.refs.values[id] = rv
+	return v, nil
+}
+
+// unpackBackRefMD returns the pointer previously deserialised with the reference id
+func (m *minData) unpackBackRefMD(data []byte) (any, error) {
+	id, _ := readLenMD(data, m.compact())
+	if id >= len(m.refs.values) || !m.refs.values[id].IsValid() {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	return m.refs.values[id].Interface(), nil
+}
@@ -0,0 +1,176 @@
+package serialise
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testShared struct {
+	A *time.Time
+	B *time.Time
+	C []*time.Time
+}
+
+type testNode struct {
+	Name string
+	Next *testNode
+}
+
+func TestReferenceTracking(t *testing.T) {
+
+	if err := Register("testShared", testShared{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Register("testNode", testNode{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tm := time.Now()
+	shared := testShared{A: &tm, B: &tm, C: []*time.Time{&tm, nil}}
+
+	tracking := NewMinDataApproach(WithReferenceTracking())
+	if tracking.Name() != NewMinDataApproach().Name() {
+		t.Fatalf("Unexpected change of name: %s", tracking.Name())
+	}
+
+	// Data created with reference tracking is deserialisable by the registered Approach
+	approach, err := GetApproach(tracking.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, _, err := ToBytes(shared, WithSerialisationApproach(tracking))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := FromBytes(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s := v.(testShared)
+	if s.A != s.B || s.A != s.C[0] || s.C[1] != nil {
+		t.Fatalf("Expected shared pointers to be preserved: %v", s)
+	}
+	if !s.A.Equal(tm) {
+		t.Fatalf("Data mismatch: expected %v, got: %v", tm, *s.A)
+	}
+
+	// Without reference tracking, shared pointers are duplicated
+	b, _, err = ToBytes(shared, WithSerialisationApproach(approach))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err = FromBytes(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	s = v.(testShared)
+	if s.A == s.B || !s.A.Equal(*s.B) {
+		t.Fatalf("Expected distinct pointers with equal values: %v", s)
+	}
+
+	// Cyclic references
+	a := &testNode{Name: "a"}
+	a.Next = &testNode{Name: "b", Next: a}
+
+	b, _, err = ToBytes(a, WithSerialisationApproach(tracking))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err = FromBytes(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	n := v.(*testNode)
+	if n.Name != "a" || n.Next.Name != "b" || n.Next.Next != n {
+		t.Fatalf("Expected cycle to be preserved: %v", n)
+	}
+
+	self := &testNode{Name: "self"}
+	self.Next = self
+	b, _, err = ToBytes([]*testNode{self, self}, WithSerialisationApproach(tracking))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err = FromBytes(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ns := v.([]*testNode)
+	if ns[0] != ns[1] || ns[0].Next != ns[0] {
+		t.Fatalf("Expected self reference to be preserved: %v", ns)
+	}
+
+	if _, err = approach.Pack(a); !errors.Is(err, ErrMinDataMaxNestingExceeded) {
+		t.Fatalf("Expected ErrMinDataMaxNestingExceeded without reference tracking, got: %v", err)
+	}
+}
+
+type testGraph struct {
+	Name  string
+	Links map[string]*testGraph
+}
+
+func TestReferenceTrackingMaps(t *testing.T) {
+
+	if err := Register("testGraph", testGraph{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tracking := NewMinDataApproach(WithReferenceTracking())
+
+	// Map iteration order is random, so each case is repeated
+	for range 50 {
+
+		// Shared pointers as map values
+		tm := time.Now()
+		other := tm.Add(time.Hour)
+		m := map[string]*time.Time{"a": &tm, "b": &other, "c": &tm, "d": &other}
+
+		b, err := tracking.Pack(m)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		v, err := tracking.Unpack(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		mv := v.(map[string]*time.Time)
+		if mv["a"] != mv["c"] || mv["b"] != mv["d"] || mv["a"] == mv["b"] || !mv["a"].Equal(tm) || !mv["b"].Equal(other) {
+			t.Fatalf("Expected shared pointers to be preserved: %v", mv)
+		}
+
+		// Shared pointers as map keys and values
+		pm := map[*time.Time]*time.Time{&tm: &other, &other: &tm}
+		if b, err = tracking.Pack(pm); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if v, err = tracking.Unpack(b); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for k, p := range v.(map[*time.Time]*time.Time) {
+			if k == p || v.(map[*time.Time]*time.Time)[p] != k {
+				t.Fatalf("Expected shared pointers to be preserved: %v", v)
+			}
+		}
+
+		// Cyclic references through a map
+		a := &testGraph{Name: "a", Links: map[string]*testGraph{}}
+		c := &testGraph{Name: "c", Links: map[string]*testGraph{"a": a}}
+		a.Links["self"] = a
+		a.Links["c"] = c
+		a.Links["c2"] = c
+
+		if b, err = tracking.Pack(a); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if v, err = tracking.Unpack(b); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		g := v.(*testGraph)
+		if g.Links["self"] != g || g.Links["c"] != g.Links["c2"] || g.Links["c"].Links["a"] != g || g.Links["c"].Name != "c" {
+			t.Fatalf("Expected cycle to be preserved: %v", g)
+		}
+	}
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"slices"
 	"time"
+	"unsafe"
 )
 
 // MinDataVersion describes a version of a MinData serialisation implementation
@@ -16,41 +20,87 @@ type MinDataVersion int8
 const (
 	UnknownVersion MinDataVersion = iota
 	V1
+	V2 // Lengths and integers are serialised as varints, with signed integers zigzag encoded
+	V3 // Numeric and bool slices are serialised using the smallest of a set of columnar encodings
+	V4 // Bool slices are bit-packed, and *BitSet is supported
+	V5 // String slices with few distinct values are dictionary encoded
 	OutOfRange
 )
 
 var defaultVersion MinDataVersion = V1
 
+// MinDataOptions adjust how MinData serialisation is performed
+type MinDataOptions struct {
+	// TrackReferences records pointers that have already been serialised as
+	// back-references, so that shared pointers and cyclic references are preserved
+	TrackReferences bool
+}
+
+// WithReferenceTracking enables reference tracking, so that pointers which are
+// reachable more than once from the value being serialised are only serialised once,
+// and are deserialised as a single shared pointer.  Cyclic references are only
+// supported when reference tracking is enabled.
+// Data serialised with reference tracking can be deserialised by any instance of
+// the same version of MinData, so that the Approach returned by GetApproach() can be used.
+func WithReferenceTracking() func(*MinDataOptions) {
+	return func(o *MinDataOptions) {
+		o.TrackReferences = true
+	}
+}
+
 // NewMinDataApproach creates an instance of the
 // current default version of the MinData serialisation
-func NewMinDataApproach() Approach {
-	return NewMinDataApproachWithVersion(defaultVersion)
+func NewMinDataApproach(opts ...func(*MinDataOptions)) Approach {
+	return NewMinDataApproachWithVersion(defaultVersion, opts...)
 }
 
 // NewMinDataApproachWithVersion creates an instance of the
-// specified version of MinData serialisation
-func NewMinDataApproachWithVersion(version MinDataVersion) Approach {
+// specified version of MinData serialisation, panicking if the version is not supported
+func NewMinDataApproachWithVersion(version MinDataVersion, opts ...func(*MinDataOptions)) Approach {
+	a, err := NewMinDataApproachForVersion(version, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("Illegal MinDataVersion passed to NewMinDataApproach (%d)", version))
+	}
+	return a
+}
+
+// ErrInvalidMinDataVersion is raised if the MinDataVersion is not supported
+var ErrInvalidMinDataVersion = errors.New("MinDataVersion is not supported")
+
+// NewMinDataApproachForVersion creates an instance of the specified version of
+// MinData serialisation, returning ErrInvalidMinDataVersion if the version is not supported.
+// Each version can deserialise data serialised by itself and by all earlier versions.
+func NewMinDataApproachForVersion(version MinDataVersion, opts ...func(*MinDataOptions)) (Approach, error) {
+	o := MinDataOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	switch version {
-	case V1:
-		name := "MD1"
-		return &minDataV1{name: name}
+	case V1, V2, V3, V4, V5:
+		name := fmt.Sprintf("MD%d", version)
+		return &minData{name: name, version: version, trackRefs: o.TrackReferences}, nil
 	default:
-		panic(fmt.Sprintf("Illegal MinDataVersion passed to NewMinDataApproach (%d)", version))
+		return nil, ErrInvalidMinDataVersion
 	}
 }
 
-type minDataV1 struct {
-	name string
+type minData struct {
+	name      string
+	version   MinDataVersion
+	trackRefs bool
+	decode    DecodeOptions
+	refs      *refStateMD
 }
 
 // Name of the approach
-func (m *minDataV1) Name() string {
+func (m *minData) Name() string {
 	return m.name
 }
 
 // IsSerialisable returns true if an instance of the specified type
 // can be serialised
-func (m *minDataV1) IsSerialisable(v any) (ok bool) {
+func (m *minData) IsSerialisable(v any) (ok bool) {
 	defer func() {
 		if r := recover(); r != nil {
 			ok = false
@@ -65,391 +115,628 @@ func (m *minDataV1) IsSerialisable(v any) (ok bool) {
 var ErrMinDataTypeNotSerialisable = errors.New("type of argument is not serialisable")
 
 // Pack serialises the instance to a byte slice
-func (m *minDataV1) Pack(data any) ([]byte, error) {
+func (m *minData) Pack(data any) ([]byte, error) {
+	return m.AppendPack(nil, data)
+}
 
-	pack := func(t TypeID, data any) ([]byte, error) {
-		var tbuf bytes.Buffer
-		var vbuf bytes.Buffer
+// AppendPack appends the serialised instance to dst, returning the extended slice
+func (m *minData) AppendPack(dst []byte, data any) ([]byte, error) {
+	if m.version >= V2 {
+		dst = append(dst, versionHeaderMD|byte(m.version))
+	}
+	return m.appendPackMD(dst, data)
+}
 
-		err := binary.Write(&tbuf, binary.LittleEndian, t)
-		if err != nil {
-			return nil, err
-		}
-		if data != nil {
-			if b, ok := data.([]byte); ok {
-				return append(tbuf.Bytes(), b...), nil
-			}
-			err = binary.Write(&tbuf, binary.LittleEndian, data)
-			if err != nil {
-				return nil, err
-			}
+// packMD serialises a value nested within the instance being serialised
+func (m *minData) packMD(data any) ([]byte, error) {
+	return m.appendPackMD(nil, data)
+}
+
+// appendPackMD appends the serialised value to dst, without the version header
+func (m *minData) appendPackMD(dst []byte, data any) ([]byte, error) {
+
+	// State is only required when serialising types that may contain pointers
+	if m.refs == nil && data != nil {
+		if _, ok := builtinTypeIDs[reflect.TypeOf(data)]; !ok {
+			return m.withRefs().appendPackMD(dst, data)
 		}
-		return append(tbuf.Bytes(), vbuf.Bytes()...), nil
 	}
 
-	packByteSliceSlice := func(t TypeID, data [][]byte) ([]byte, error) {
-		var buf bytes.Buffer
-
-		err := binary.Write(&buf, binary.LittleEndian, t)
-		if err != nil {
+	if m.refs != nil {
+		if err := m.refs.enter(); err != nil {
 			return nil, err
 		}
-		if data != nil {
-			var size int64 = int64(len(data))
-			err = binary.Write(&buf, binary.LittleEndian, size)
-			if err != nil {
-				return nil, err
-			}
-			for _, b := range data {
-				size = int64(len(b))
-				err = binary.Write(&buf, binary.LittleEndian, size)
-				if err != nil {
-					return nil, err
-				}
-				if size > 0 {
-					err = binary.Write(&buf, binary.LittleEndian, b)
-					if err != nil {
-						return nil, err
-					}
-				}
+		defer m.refs.leave()
+
+		if m.trackRefs {
+			var seen bool
+			if dst, seen = m.refs.appendRef(dst, data, m.compact()); seen {
+				return dst, nil
 			}
 		}
-		return buf.Bytes(), nil
 	}
 
-	packTime := func(t TypeID, tm *time.Time) ([]byte, error) {
-		var buf bytes.Buffer
-
-		err := binary.Write(&buf, binary.LittleEndian, t)
-		if err != nil {
-			return nil, err
-		}
+	return m.appendValue(dst, data)
+}
 
-		b, err := tm.MarshalBinary()
-		if err != nil {
-			return nil, err
-		}
+// appendValue appends the serialised instance to b.  Types that are natively
+// supported are written directly, without reflection or per-element allocation.
+func (m *minData) appendValue(b []byte, data any) ([]byte, error) {
 
-		return append(buf.Bytes(), b...), nil
+	// Nil pointers are recorded as TypedNilType followed by the type of the pointer,
+	// so that the type of the pointer is retained when deserialised
+	typedNil := func(t TypeID) ([]byte, error) {
+		return append(b, byte(TypedNilType), byte(t)), nil
 	}
 
 	if data == nil {
-		return pack(NilType, nil)
+		return append(b, byte(NilType)), nil
 	}
 
+	c := m.compact()
+
 	switch v := data.(type) {
 	case int8:
-		return pack(Int8Type, data)
+		return appendFixedMD(append(b, byte(Int8Type)), v), nil
 	case *int8:
-		return pack(Pint8Type, data)
+		if v == nil {
+			return typedNil(Pint8Type)
+		}
+		return appendFixedMD(append(b, byte(Pint8Type)), *v), nil
 	case []int8:
-		return packSimpleSliceMD(Int8SliceType, v)
+		return appendSimpleSliceMD(b, Int8SliceType, v, c), nil
 	case int16:
-		return pack(Int16Type, data)
+		return appendIntMD(append(b, byte(Int16Type)), v, c), nil
 	case *int16:
-		return pack(Pint16Type, data)
+		if v == nil {
+			return typedNil(Pint16Type)
+		}
+		return appendIntMD(append(b, byte(Pint16Type)), *v, c), nil
 	case []int16:
-		return packSimpleSliceMD(Int16SliceType, v)
+		return appendIntSliceMD(b, Int16SliceType, v, m.version), nil
 	case int32:
-		return pack(Int32Type, data)
+		return appendIntMD(append(b, byte(Int32Type)), v, c), nil
 	case *int32:
-		return pack(Pint32Type, data)
+		if v == nil {
+			return typedNil(Pint32Type)
+		}
+		return appendIntMD(append(b, byte(Pint32Type)), *v, c), nil
 	case []int32:
-		return packSimpleSliceMD(Int32SliceType, v)
+		return appendIntSliceMD(b, Int32SliceType, v, m.version), nil
 	case int64:
-		return pack(Int64Type, data)
+		return appendIntMD(append(b, byte(Int64Type)), v, c), nil
 	case *int64:
-		return pack(Pint64Type, data)
+		if v == nil {
+			return typedNil(Pint64Type)
+		}
+		return appendIntMD(append(b, byte(Pint64Type)), *v, c), nil
 	case []int64:
-		return packSimpleSliceMD(Int64SliceType, v)
+		return appendIntSliceMD(b, Int64SliceType, v, m.version), nil
 	case uint8:
-		return pack(Uint8Type, data)
+		return appendFixedMD(append(b, byte(Uint8Type)), v), nil
 	case *uint8:
-		return pack(Puint8Type, data)
+		if v == nil {
+			return typedNil(Puint8Type)
+		}
+		return appendFixedMD(append(b, byte(Puint8Type)), *v), nil
 	case uint16:
-		return pack(Uint16Type, data)
+		return appendIntMD(append(b, byte(Uint16Type)), v, c), nil
 	case *uint16:
-		return pack(Puint16Type, data)
+		if v == nil {
+			return typedNil(Puint16Type)
+		}
+		return appendIntMD(append(b, byte(Puint16Type)), *v, c), nil
 	case []uint16:
-		return packSimpleSliceMD(Uint16SliceType, v)
+		return appendIntSliceMD(b, Uint16SliceType, v, m.version), nil
 	case uint32:
-		return pack(Uint32Type, data)
+		return appendIntMD(append(b, byte(Uint32Type)), v, c), nil
 	case *uint32:
-		return pack(Puint32Type, data)
+		if v == nil {
+			return typedNil(Puint32Type)
+		}
+		return appendIntMD(append(b, byte(Puint32Type)), *v, c), nil
 	case []uint32:
-		return packSimpleSliceMD(Uint32SliceType, v)
+		return appendIntSliceMD(b, Uint32SliceType, v, m.version), nil
 	case uint64:
-		return pack(Uint64Type, data)
+		return appendIntMD(append(b, byte(Uint64Type)), v, c), nil
 	case *uint64:
-		return pack(Puint64Type, data)
+		if v == nil {
+			return typedNil(Puint64Type)
+		}
+		return appendIntMD(append(b, byte(Puint64Type)), *v, c), nil
 	case []uint64:
-		return packSimpleSliceMD(Uint64SliceType, v)
+		return appendIntSliceMD(b, Uint64SliceType, v, m.version), nil
 	case float32:
-		return pack(Float32Type, data)
+		return appendFixedMD(append(b, byte(Float32Type)), math.Float32bits(v)), nil
 	case *float32:
-		return pack(Pfloat32Type, data)
+		if v == nil {
+			return typedNil(Pfloat32Type)
+		}
+		return appendFixedMD(append(b, byte(Pfloat32Type)), math.Float32bits(*v)), nil
 	case []float32:
-		return packSimpleSliceMD(Float32SliceType, v)
+		return appendFloat32SliceMD(b, Float32SliceType, v, m.version), nil
 	case float64:
-		return pack(Float64Type, data)
+		return appendFixedMD(append(b, byte(Float64Type)), math.Float64bits(v)), nil
 	case *float64:
-		return pack(Pfloat64Type, data)
+		if v == nil {
+			return typedNil(Pfloat64Type)
+		}
+		return appendFixedMD(append(b, byte(Pfloat64Type)), math.Float64bits(*v)), nil
 	case []float64:
-		return packSimpleSliceMD(Float64SliceType, v)
+		return appendFloat64SliceMD(b, Float64SliceType, v, m.version), nil
 	case bool:
-		return pack(BoolType, data)
+		return appendBoolMD(append(b, byte(BoolType)), v), nil
 	case *bool:
-		return pack(PboolType, data)
+		if v == nil {
+			return typedNil(PboolType)
+		}
+		return appendBoolMD(append(b, byte(PboolType)), *v), nil
 	case []bool:
-		return packSimpleSliceMD(BoolSliceType, v)
+		return appendBoolSliceMD(b, BoolSliceType, v, m.version), nil
+	case *BitSet:
+		if m.version < V4 {
+			return nil, ErrMinDataTypeNotSerialisable
+		}
+		if v == nil {
+			return typedNil(BitSetType)
+		}
+		return appendBitSetMD(append(b, byte(BitSetType), byte(bitPackedEncodingMD)), v), nil
 	case time.Duration:
-		return pack(DurationType, data)
+		return appendIntMD(append(b, byte(DurationType)), v, c), nil
 	case *time.Duration:
-		return pack(PdurationType, data)
+		if v == nil {
+			return typedNil(PdurationType)
+		}
+		return appendIntMD(append(b, byte(PdurationType)), *v, c), nil
 	case []time.Duration:
-		return packSimpleSliceMD(DurationSliceType, v)
+		return appendIntSliceMD(b, DurationSliceType, v, m.version), nil
 	case time.Time:
-		return packTime(TimeType, &v)
+		return appendTimeMD(append(b, byte(TimeType)), &v)
 	case *time.Time:
-		return packTime(PtimeType, v)
+		if v == nil {
+			return typedNil(PtimeType)
+		}
+		return appendTimeMD(append(b, byte(PtimeType)), v)
 	case string:
-		return pack(StringType, []byte(v))
+		return append(append(b, byte(StringType)), v...), nil
 	case *string:
-		return pack(PstringType, []byte(*v))
-	case []string:
-		var bss [][]byte = make([][]byte, len(v))
-		for i := 0; i < len(v); i++ {
-			bss[i] = []byte(v[i])
+		if v == nil {
+			return typedNil(PstringType)
 		}
-		return packByteSliceSlice(StringSliceType, bss)
+		return append(append(b, byte(PstringType)), *v...), nil
+	case []string:
+		return appendStringSliceMD(b, StringSliceType, v, m.version), nil
 	case []byte:
-		return pack(ByteSliceType, v)
+		return append(append(b, byte(ByteSliceType)), v...), nil
 	case [][]byte:
-		return packByteSliceSlice(ByteSliceSliceType, v)
+		if v == nil {
+			// A nil [][]byte is recorded without a length
+			return append(b, byte(ByteSliceSliceType)), nil
+		}
+		return appendByteSliceSliceMD(b, ByteSliceSliceType, v, c), nil
 	default:
-		return nil, ErrMinDataTypeNotSerialisable
+		rb, err := m.packReflect(data)
+		if err != nil {
+			return nil, err
+		}
+		return append(b, rb...), nil
 	}
 }
 
+// UnpackWithOptions deserialises an instance from the byte slice, as adjusted by opts.
+// By default, []byte and string values (including those within slices, maps and structs)
+// are copied, so that the instance is independent of data.  If opts.ZeroCopy is set then
+// these values alias data, which must then not be modified while the instance is in use.
+// Slices of numeric and bool values are always independent of data.
+// If opts.BoolsAsBitSet is set then a []bool is deserialised as a *BitSet, and if
+// opts.Interner is set then string values are obtained from the Interner.
+func (m *minData) UnpackWithOptions(data []byte, opts DecodeOptions) (any, error) {
+	if opts.ZeroCopy == m.decode.ZeroCopy && opts.BoolsAsBitSet == m.decode.BoolsAsBitSet &&
+		opts.Interner == nil && m.decode.Interner == nil {
+		return m.Unpack(data)
+	}
+
+	mm := *m
+	mm.decode = opts
+	return mm.Unpack(data)
+}
+
+// bytesMD returns b, or an independent copy of b if zero copy deserialisation is not in use
+func (m *minData) bytesMD(b []byte) []byte {
+	if m.decode.ZeroCopy {
+		return b[:len(b):len(b)]
+	}
+	return bytes.Clone(b)
+}
+
+// stringMD returns b as a string, which is provided by the Interner if one is in use,
+// or otherwise aliases b if zero copy deserialisation is in use
+func (m *minData) stringMD(b []byte) string {
+	if m.decode.Interner != nil {
+		return m.decode.Interner.Intern(b)
+	}
+	if m.decode.ZeroCopy && len(b) > 0 {
+		return unsafe.String(&b[0], len(b))
+	}
+	return string(b)
+}
+
 // ErrMinDataTypeNotDeserialisable is raised if a variable type is not deserialisable by the MinData approach
 var ErrMinDataTypeNotDeserialisable = errors.New("type specified within the data is not deserialisable")
 
 // ErrUnexpectedDeserialisationError is raised if a panic is generated during deserialisation
 var ErrUnexpectedDeserialisationError = errors.New("unexpected deserialisation failure - possible corrupted data provided")
 
-// Unpack deserialises an instance from the byte slice
-// func (m *minData) Unpack(data []byte, opts ...func(opt *TypeRegistryOptions)) (output any, e error) {
-func (m *minDataV1) Unpack(data []byte) (output any, e error) {
+// ErrUnknownTypeName is raised if a type name within the data has not been registered using Register()
+var ErrUnknownTypeName = errors.New("type name specified within the data is not registered")
 
-	defer func() {
-		if r := recover(); r != nil {
-			output = nil
-			e = ErrUnexpectedDeserialisationError
-		}
-	}()
+// ErrMinDataVersionMismatch is raised if the data was serialised by a later version of MinData
+var ErrMinDataVersionMismatch = errors.New("data was serialised by a later version of MinData")
 
-	var t TypeID
-	err := binary.Read(bytes.NewBuffer(data[0:1]), binary.LittleEndian, &t)
+// Unpack deserialises an instance from the byte slice.  Data serialised by earlier
+// versions of MinData is deserialised using the rules of the version that serialised it,
+// which is identified from the version header (or its absence, for V1).
+func (m *minData) Unpack(data []byte) (any, error) {
+	version, data, err := readVersionMD(data)
 	if err != nil {
 		return nil, err
 	}
+	if version > m.version {
+		return nil, ErrMinDataVersionMismatch
+	}
+	if version != m.version {
+		mm := *m
+		mm.version = version
+		return mm.unpackVersionMD(data)
+	}
+	return m.unpackVersionMD(data)
+}
 
-	unpackPtr := func(v any, data []byte) (any, error) {
-		err := binary.Read(bytes.NewBuffer(data[1:]), binary.LittleEndian, v)
-		if err != nil {
-			return nil, err
-		}
-		return v, nil
+// readVersionMD returns the version of MinData that serialised the data,
+// and the data that follows the version header
+func readVersionMD(data []byte) (MinDataVersion, []byte, error) {
+	if len(data) == 0 {
+		return UnknownVersion, nil, ErrUnexpectedDeserialisationError
+	}
+	if data[0]&versionHeaderMD == 0 {
+		return V1, data, nil
 	}
 
-	unpackByteSliceSlice := func(data []byte) ([][]byte, error) {
-		var size int64
-		err := binary.Read(bytes.NewBuffer(data), binary.LittleEndian, &size)
-		if err != nil {
-			return nil, err
-		}
+	version := MinDataVersion(data[0] &^ versionHeaderMD)
+	switch {
+	case version < V2:
+		return UnknownVersion, nil, ErrUnexpectedDeserialisationError
+	case version >= OutOfRange:
+		return UnknownVersion, nil, ErrMinDataVersionMismatch
+	}
+	return version, data[1:], nil
+}
 
-		bss := make([][]byte, size)
-		var i int64
-		var offset int64 = 8
-		for i = 0; i < size; i++ {
-			var itemSize int64
-			err := binary.Read(bytes.NewBuffer(data[offset:]), binary.LittleEndian, &itemSize)
-			if err != nil {
-				return nil, err
-			}
-			bss[i] = data[offset+8 : offset+8+itemSize]
-			offset += 8 + itemSize
-		}
-		return bss, nil
+// unpackVersionMD deserialises the instance that follows the version header
+func (m *minData) unpackVersionMD(data []byte) (any, error) {
+	if m.decode.BoolsAsBitSet && len(data) > 0 && TypeID(data[0]) == BoolSliceType {
+		return m.unpackBoolsAsBitSetMD(data[1:])
 	}
+	return m.unpackMD(data)
+}
 
-	unpackTime := func(data []byte) (any, error) {
-		tm := new(time.Time)
-		err := tm.UnmarshalBinary(data)
-		if err != nil {
+// unpackMD deserialises a value, without the version header
+func (m *minData) unpackMD(data []byte) (output any, e error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			output = nil
+			e = ErrUnexpectedDeserialisationError
+		}
+	}()
+
+	t := TypeID(data[0])
+
+	// State is only required when deserialising types that may contain pointers
+	if m.refs == nil {
+		if _, ok := builtinTypes[t]; !ok {
+			return m.withRefs().unpackMD(data)
+		}
+	} else {
+		if err := m.refs.enter(); err != nil {
 			return nil, err
 		}
-		return tm, nil
+		defer m.refs.leave()
 	}
 
+	c := m.compact()
+
 	switch t {
 	case NilType:
 		return nil, nil
+	case TypedNilType:
+		return m.unpackTypedNilMD(data[1:])
 	case Int8Type:
-		return unpackMD[int8](data)
+		return readFixedMD[int8](data[1:]), nil
 	case Pint8Type:
-		return unpackPtr(new(int8), data)
+		return ptrMD(readFixedMD[int8](data[1:])), nil
 	case Int8SliceType:
-		return unpackSimpleSliceMD[int8](data[1:], 1)
+		return readSimpleSliceMD[int8](data[1:], 1, c)
 	case Int16Type:
-		return unpackMD[int16](data)
+		return readIntMD[int16](data[1:], c)
 	case Pint16Type:
-		return unpackPtr(new(int16), data)
+		return ptrOrErrMD(readIntMD[int16](data[1:], c))
 	case Int16SliceType:
-		return unpackSimpleSliceMD[int16](data[1:], 2)
+		return unpackIntSliceMD[int16](data[1:], m.version)
 	case Int32Type:
-		return unpackMD[int32](data)
+		return readIntMD[int32](data[1:], c)
 	case Pint32Type:
-		return unpackPtr(new(int32), data)
+		return ptrOrErrMD(readIntMD[int32](data[1:], c))
 	case Int32SliceType:
-		return unpackSimpleSliceMD[int32](data[1:], 4)
+		return unpackIntSliceMD[int32](data[1:], m.version)
 	case Int64Type:
-		return unpackMD[int64](data)
+		return readIntMD[int64](data[1:], c)
 	case Pint64Type:
-		return unpackPtr(new(int64), data)
+		return ptrOrErrMD(readIntMD[int64](data[1:], c))
 	case Int64SliceType:
-		return unpackSimpleSliceMD[int64](data[1:], 8)
+		return unpackIntSliceMD[int64](data[1:], m.version)
 	case Uint8Type:
-		return unpackMD[uint8](data)
+		return readFixedMD[uint8](data[1:]), nil
 	case Puint8Type:
-		return unpackPtr(new(uint8), data)
+		return ptrMD(readFixedMD[uint8](data[1:])), nil
 	case Uint16Type:
-		return unpackMD[uint16](data)
+		return readIntMD[uint16](data[1:], c)
 	case Puint16Type:
-		return unpackPtr(new(uint16), data)
+		return ptrOrErrMD(readIntMD[uint16](data[1:], c))
 	case Uint16SliceType:
-		return unpackSimpleSliceMD[uint16](data[1:], 2)
+		return unpackIntSliceMD[uint16](data[1:], m.version)
 	case Uint32Type:
-		return unpackMD[uint32](data)
+		return readIntMD[uint32](data[1:], c)
 	case Puint32Type:
-		return unpackPtr(new(uint32), data)
+		return ptrOrErrMD(readIntMD[uint32](data[1:], c))
 	case Uint32SliceType:
-		return unpackSimpleSliceMD[uint32](data[1:], 4)
+		return unpackIntSliceMD[uint32](data[1:], m.version)
 	case Uint64Type:
-		return unpackMD[uint64](data)
+		return readIntMD[uint64](data[1:], c)
 	case Puint64Type:
-		return unpackPtr(new(uint64), data)
+		return ptrOrErrMD(readIntMD[uint64](data[1:], c))
 	case Uint64SliceType:
-		return unpackSimpleSliceMD[uint64](data[1:], 8)
+		return unpackIntSliceMD[uint64](data[1:], m.version)
 	case Float32Type:
-		return unpackMD[float32](data)
+		return math.Float32frombits(readFixedMD[uint32](data[1:])), nil
 	case Pfloat32Type:
-		return unpackPtr(new(float32), data)
+		return ptrMD(math.Float32frombits(readFixedMD[uint32](data[1:]))), nil
 	case Float32SliceType:
-		return unpackSimpleSliceMD[float32](data[1:], 4)
+		return unpackFloat32SliceMD(data[1:], m.version)
 	case Float64Type:
-		return unpackMD[float64](data)
+		return math.Float64frombits(readFixedMD[uint64](data[1:])), nil
 	case Pfloat64Type:
-		return unpackPtr(new(float64), data)
+		return ptrMD(math.Float64frombits(readFixedMD[uint64](data[1:]))), nil
 	case Float64SliceType:
-		return unpackSimpleSliceMD[float64](data[1:], 8)
+		return unpackFloat64SliceMD(data[1:], m.version)
 	case BoolType:
-		return unpackMD[bool](data)
+		return data[1] != 0, nil
 	case PboolType:
-		return unpackPtr(new(bool), data)
+		return ptrMD(data[1] != 0), nil
 	case BoolSliceType:
-		return unpackSimpleSliceMD[bool](data[1:], 1)
+		return unpackBoolSliceMD(data[1:], m.version)
+	case BitSetType:
+		if m.version < V4 || sliceEncodingMD(data[1]) != bitPackedEncodingMD {
+			return nil, ErrMinDataTypeNotDeserialisable
+		}
+		return readBitSetMD(data[2:])
 	case DurationType:
-		return unpackMD[time.Duration](data)
+		return readIntMD[time.Duration](data[1:], c)
 	case PdurationType:
-		return unpackPtr(new(time.Duration), data)
+		return ptrOrErrMD(readIntMD[time.Duration](data[1:], c))
 	case DurationSliceType:
-		return unpackSimpleSliceMD[time.Duration](data[1:], 8)
+		return unpackIntSliceMD[time.Duration](data[1:], m.version)
 	case TimeType:
-		tm, err := unpackTime(data[1:])
-		return *(tm.(*time.Time)), err
+		return unpackTimeMD(data[1:])
 	case PtimeType:
-		return unpackTime(data[1:])
+		tm, err := unpackTimeMD(data[1:])
+		if err != nil {
+			return nil, err
+		}
+		return &tm, nil
 	case StringType:
-		return string(data[1:]), nil
+		return m.stringMD(data[1:]), nil
 	case PstringType:
-		s := string(data[1:])
+		s := m.stringMD(data[1:])
 		return &s, nil
 	case ByteSliceType:
-		return data[1:], nil
+		return m.bytesMD(data[1:]), nil
 	case ByteSliceSliceType:
-		return unpackByteSliceSlice(data[1:])
-	case StringSliceType:
-		bss, err := unpackByteSliceSlice(data[1:])
+		bss, err := unpackByteSliceSliceMD(data[1:], c)
 		if err != nil {
 			return nil, err
 		}
-		ss := make([]string, len(bss))
-		for i := 0; i < len(bss); i++ {
-			ss[i] = string(bss[i])
+		for i := range bss {
+			bss[i] = m.bytesMD(bss[i])
 		}
-		return ss, nil
+		return bss, nil
+	case StringSliceType:
+		return m.unpackStringSliceMD(data[1:])
+	case BinaryMarshalerType, TextMarshalerType:
+		return m.unpackMarshalerMD(t, data[1:])
+	case SliceType:
+		return m.unpackSliceMD(data[1:])
+	case MapType:
+		return m.unpackMapMD(data[1:])
+	case NamedType:
+		return m.unpackNamedMD(data[1:])
+	case StructType:
+		return m.unpackStructMD(data[1:])
+	case PointerType:
+		return m.unpackPointerMD(data[1:])
+	case RefType:
+		return m.unpackRefMD(data[1:])
+	case BackRefType:
+		return m.unpackBackRefMD(data[1:])
 	default:
-		return nil, ErrMinDataTypeNotDeserialisable
+		return unpackUserTypeMD(t, data[1:])
 	}
 }
 
-func packSimpleSliceMD[T any](t TypeID, data []T) ([]byte, error) {
-	var buf bytes.Buffer
+// nativeLittleEndianMD is true if the host stores values in little endian order,
+// allowing slices of fixed width values to be copied directly
+var nativeLittleEndianMD = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
 
-	err := binary.Write(&buf, binary.LittleEndian, t)
-	if err != nil {
-		return nil, err
+// fixedMD are the integer types that are serialised as fixed width little endian values
+type fixedMD interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// appendFixedMD appends the little endian representation of v to b
+func appendFixedMD[T fixedMD](b []byte, v T) []byte {
+	switch unsafe.Sizeof(v) {
+	case 1:
+		return append(b, byte(v))
+	case 2:
+		return binary.LittleEndian.AppendUint16(b, uint16(v))
+	case 4:
+		return binary.LittleEndian.AppendUint32(b, uint32(v))
+	default:
+		return binary.LittleEndian.AppendUint64(b, uint64(v))
 	}
+}
 
-	err = binary.Write(&buf, binary.LittleEndian, int64(len(data)))
-	if err != nil {
-		return nil, err
+// readFixedMD reads a value created by appendFixedMD
+func readFixedMD[T fixedMD](data []byte) T {
+	var v T
+	switch unsafe.Sizeof(v) {
+	case 1:
+		return T(data[0])
+	case 2:
+		return T(binary.LittleEndian.Uint16(data))
+	case 4:
+		return T(binary.LittleEndian.Uint32(data))
+	default:
+		return T(binary.LittleEndian.Uint64(data))
 	}
+}
 
-	for _, d := range data {
-		err = binary.Write(&buf, binary.LittleEndian, d)
-		if err != nil {
-			return nil, err
-		}
+func appendBoolMD(b []byte, v bool) []byte {
+	if v {
+		return append(b, 1)
 	}
+	return append(b, 0)
+}
 
-	return buf.Bytes(), nil
+func ptrMD[T any](v T) *T {
+	return &v
 }
 
-func unpackSimpleSliceMD[T any](data []byte, eleSize int64) (any, error) {
-	var size int64
-	err := binary.Read(bytes.NewBuffer(data), binary.LittleEndian, &size)
+func appendTimeMD(b []byte, tm *time.Time) ([]byte, error) {
+	tb, err := tm.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
+	return append(b, tb...), nil
+}
 
-	var v = make([]T, size)
+func unpackTimeMD(data []byte) (time.Time, error) {
+	var tm time.Time
+	err := tm.UnmarshalBinary(data)
+	return tm, err
+}
 
-	var i int64
-	for i = 0; i < size; i++ {
-		var vv T
-		err := binary.Read(bytes.NewBuffer(data[8+i*eleSize:]), binary.LittleEndian, &vv)
-		if err != nil {
-			return nil, err
-		}
-		v[i] = vv
+// bytesOfMD returns the memory of the slice as a byte slice
+func bytesOfMD[T any](data []T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(data))), len(data)*int(unsafe.Sizeof(*new(T))))
+}
+
+// swapEachMD reverses the byte order of each value within b, converting
+// between the host byte order and little endian on big endian hosts
+func swapEachMD(b []byte, size int) {
+	for i := 0; i+size <= len(b); i += size {
+		slices.Reverse(b[i : i+size])
 	}
+}
+
+// appendSimpleSliceMD appends a slice of fixed width values (integers, floats, bools)
+// as its length followed by the little endian representation of each value
+func appendSimpleSliceMD[T any](b []byte, t TypeID, data []T, compact bool) []byte {
+	raw := bytesOfMD(data)
 
-	return v, nil
+	b = slices.Grow(b, 9+len(raw))
+	b = append(b, byte(t))
+	b = appendLenMD(b, len(data), compact)
+
+	n := len(b)
+	b = append(b, raw...)
+	if !nativeLittleEndianMD {
+		swapEachMD(b[n:], int(unsafe.Sizeof(*new(T))))
+	}
+	return b
 }
 
-func unpackMD[T any](data []byte) (T, error) {
-	unpack := func(v any, data []byte) error {
-		err := binary.Read(bytes.NewBuffer(data[1:]), binary.LittleEndian, v)
-		if err != nil {
-			return err
+// readSimpleSliceMD deserialises a slice created by appendSimpleSliceMD
+func readSimpleSliceMD[T any](data []byte, eleSize int64, compact bool) (any, error) {
+	size, n := readLenMD(data, compact)
+	data = data[n:]
+	if size < 0 || int64(size) > int64(len(data))/eleSize {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := make([]T, size)
+	var out any = v
+
+	// Only 0 and 1 are valid in the memory of a bool, so these are read individually
+	if bs, ok := out.([]bool); ok {
+		for i := range bs {
+			bs[i] = data[i] != 0
 		}
-		return nil
+		return out, nil
 	}
 
-	var v T
-	err := unpack(&v, data)
-	return v, err
+	raw := bytesOfMD(v)
+	copy(raw, data)
+	if !nativeLittleEndianMD {
+		swapEachMD(raw, int(eleSize))
+	}
+	return out, nil
+}
+
+// appendByteSliceSliceMD appends the number of items, followed by each item prefixed with its length
+func appendByteSliceSliceMD[S ~string | ~[]byte](b []byte, t TypeID, data []S, compact bool) []byte {
+	return appendLenPrefixedMD(append(b, byte(t)), data, compact)
+}
+
+// appendLenPrefixedMD appends the number of items, followed by each item prefixed with its length
+func appendLenPrefixedMD[S ~string | ~[]byte](b []byte, data []S, compact bool) []byte {
+	size := 8 + 8*len(data)
+	for _, d := range data {
+		size += len(d)
+	}
+
+	b = slices.Grow(b, size)
+	b = appendLenMD(b, len(data), compact)
+	for _, d := range data {
+		b = appendLenMD(b, len(d), compact)
+		b = append(b, d...)
+	}
+	return b
+}
+
+func unpackByteSliceSliceMD(data []byte, compact bool) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	bss, _, err := readLenPrefixedMD(data, compact)
+	return bss, err
+}
+
+// readLenPrefixedMD reads items created by appendLenPrefixedMD, returning the
+// items and the number of bytes read.  The items alias data.
+func readLenPrefixedMD(data []byte, compact bool) ([][]byte, int, error) {
+	size, offset := readLenMD(data, compact)
+	if size < 0 || size > (len(data)-offset)/lenWidthMD(compact) {
+		return nil, 0, ErrUnexpectedDeserialisationError
+	}
+
+	bss := make([][]byte, size)
+	for i := range bss {
+		itemSize, n := readLenMD(data[offset:], compact)
+		offset += n
+		bss[i] = data[offset : offset+itemSize : offset+itemSize]
+		offset += itemSize
+	}
+	return bss, offset, nil
 }
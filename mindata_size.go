@@ -0,0 +1,187 @@
+package serialise
+
+import (
+	"reflect"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// maxPooledSizeBufferMD limits the capacity of buffers retained by sizeBufferPool
+const maxPooledSizeBufferMD = 1 << 20
+
+// sizeBufferPool holds the buffers used to determine the length of data that
+// cannot be calculated directly
+var sizeBufferPool = sync.Pool{
+	New: func() any {
+		return new([]byte)
+	},
+}
+
+// SizeOf returns the exact length of the data when serialised using Pack.
+// For V1, the length of natively supported types is calculated without serialising the data;
+// other types (structs, maps, registered types etc.), and all types for versions using
+// variable length encodings, are serialised into a pooled buffer to determine their length,
+// so that the serialised data is not allocated.
+func (m *minData) SizeOf(data any) (int, error) {
+	if size, ok, err := m.quickSizeOf(data); ok || err != nil {
+		return size, err
+	}
+
+	pb := sizeBufferPool.Get().(*[]byte)
+	defer sizeBufferPool.Put(pb)
+
+	b, err := m.AppendPack((*pb)[:0], data)
+	if err != nil {
+		return 0, err
+	}
+	if cap(b) <= maxPooledSizeBufferMD {
+		*pb = b
+	}
+	return len(b), nil
+}
+
+// quickSizeOf returns the length of the data when serialised using Pack, if the length can be
+// determined without serialising the data, which is only the case for natively supported types
+// in V1.  False is returned for other types and versions, allowing ToBytesMany to serialise each item once.
+func (m *minData) quickSizeOf(data any) (int, bool, error) {
+	if m.compact() {
+		return 0, false, nil
+	}
+	if data != nil {
+		if _, ok := builtinTypeIDs[reflect.TypeOf(data)]; !ok {
+			return 0, false, nil
+		}
+	}
+	size, err := sizeValueMD(data)
+	return size, true, err
+}
+
+// sizeValueMD returns the length of the serialised data for types that are natively supported,
+// consistent with appendValue
+func sizeValueMD(data any) (int, error) {
+	switch v := data.(type) {
+	case nil:
+		return 1, nil
+	case int8, uint8, bool:
+		return 2, nil
+	case int16, uint16:
+		return 3, nil
+	case int32, uint32, float32:
+		return 5, nil
+	case int64, uint64, float64, time.Duration:
+		return 9, nil
+	case *int8:
+		return sizePtrMD(v), nil
+	case *int16:
+		return sizePtrMD(v), nil
+	case *int32:
+		return sizePtrMD(v), nil
+	case *int64:
+		return sizePtrMD(v), nil
+	case *uint8:
+		return sizePtrMD(v), nil
+	case *uint16:
+		return sizePtrMD(v), nil
+	case *uint32:
+		return sizePtrMD(v), nil
+	case *uint64:
+		return sizePtrMD(v), nil
+	case *float32:
+		return sizePtrMD(v), nil
+	case *float64:
+		return sizePtrMD(v), nil
+	case *bool:
+		return sizePtrMD(v), nil
+	case *time.Duration:
+		return sizePtrMD(v), nil
+	case []int8:
+		return sizeSimpleSliceMD(v), nil
+	case []int16:
+		return sizeSimpleSliceMD(v), nil
+	case []int32:
+		return sizeSimpleSliceMD(v), nil
+	case []int64:
+		return sizeSimpleSliceMD(v), nil
+	case []uint16:
+		return sizeSimpleSliceMD(v), nil
+	case []uint32:
+		return sizeSimpleSliceMD(v), nil
+	case []uint64:
+		return sizeSimpleSliceMD(v), nil
+	case []float32:
+		return sizeSimpleSliceMD(v), nil
+	case []float64:
+		return sizeSimpleSliceMD(v), nil
+	case []bool:
+		return sizeSimpleSliceMD(v), nil
+	case []time.Duration:
+		return sizeSimpleSliceMD(v), nil
+	case time.Time:
+		return sizeTimeMD(&v)
+	case *time.Time:
+		if v == nil {
+			return 2, nil
+		}
+		return sizeTimeMD(v)
+	case string:
+		return 1 + len(v), nil
+	case *string:
+		if v == nil {
+			return 2, nil
+		}
+		return 1 + len(*v), nil
+	case []byte:
+		return 1 + len(v), nil
+	case []string:
+		return sizeByteSliceSliceMD(v), nil
+	case [][]byte:
+		if v == nil {
+			return 1, nil
+		}
+		return sizeByteSliceSliceMD(v), nil
+	default:
+		return 0, ErrMinDataTypeNotSerialisable
+	}
+}
+
+// sizePtrMD returns the length of a pointer to a fixed width value, or of a typed nil
+func sizePtrMD[T any](v *T) int {
+	if v == nil {
+		return 2
+	}
+	return 1 + int(unsafe.Sizeof(*v))
+}
+
+// sizeSimpleSliceMD returns the length of a slice written by appendSimpleSliceMD
+func sizeSimpleSliceMD[T any](data []T) int {
+	return 9 + len(data)*int(unsafe.Sizeof(*new(T)))
+}
+
+// sizeByteSliceSliceMD returns the length of a slice written by appendByteSliceSliceMD
+func sizeByteSliceSliceMD[S ~string | ~[]byte](data []S) int {
+	size := 9 + 8*len(data)
+	for _, d := range data {
+		size += len(d)
+	}
+	return size
+}
+
+// sizeTimeMD returns the length of a time written by appendTimeMD, which is
+// the type byte followed by the output of time.Time.MarshalBinary
+func sizeTimeMD(tm *time.Time) (int, error) {
+	if tm.Location() == time.UTC {
+		return 16, nil
+	}
+
+	_, offset := tm.Zone()
+	if offset/60 < -32768 || offset/60 == -1 || offset/60 > 32767 {
+		// Out of range offsets cannot be serialised, so return the error from MarshalBinary
+		_, err := tm.MarshalBinary()
+		return 0, err
+	}
+	if offset%60 != 0 {
+		return 17, nil // Offsets with seconds require the longer format
+	}
+	return 16, nil
+}
@@ -0,0 +1,149 @@
+package serialise
+
+import (
+	"bytes"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNoSizer hides the optional extensions of the wrapped Approach
+type testNoSizer struct {
+	Approach
+}
+
+// testCountingSizer counts the serialisations performed by the wrapped Approach
+type testCountingSizer struct {
+	Approach
+	packs atomic.Int64
+}
+
+func (c *testCountingSizer) Pack(data any) ([]byte, error) {
+	c.packs.Add(1)
+	return c.Approach.Pack(data)
+}
+
+func (c *testCountingSizer) AppendPack(dst []byte, data any) ([]byte, error) {
+	c.packs.Add(1)
+	return c.Approach.(AppendPacker).AppendPack(dst, data)
+}
+
+func (c *testCountingSizer) SizeOf(data any) (int, error) {
+	return c.Approach.(Sizer).SizeOf(data)
+}
+
+func (c *testCountingSizer) quickSizeOf(data any) (int, bool, error) {
+	return c.Approach.(quickSizer).quickSizeOf(data)
+}
+
+func TestSizeOf(t *testing.T) {
+
+	if err := Register("testNode", testNode{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tm := time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC)
+	local := tm.In(time.FixedZone("EST", -5*60*60))
+	lmt := tm.In(time.FixedZone("LMT", -(4*60*60 + 56*60 + 2)))
+	s := "Hello World"
+	f := 3.14
+	var nilInt *int64
+
+	tests := []any{
+		nil, int8(-1), uint8(1), true, int16(-2), uint16(2), int32(-3), uint32(3), float32(1.5),
+		int64(-4), uint64(4), 2.5, time.Second, &f, nilInt, ptrMD(time.Minute), ptrMD(false),
+		[]int8{1, 2}, []int16{}, []int32{1}, []int64{1, 2, 3}, []uint16{1}, []uint32{}, []uint64{1},
+		[]float32{1}, []float64{1, 2}, []bool{true, false, true}, []time.Duration{time.Hour},
+		tm, local, lmt, &tm, (*time.Time)(nil), s, &s, (*string)(nil), []byte("abc"), []byte{},
+		[]string{"a", "", "bcd"}, [][]byte{[]byte("a"), nil}, [][]byte(nil), [][]byte{},
+		map[string]int64{"a": 1}, []any{int8(1), "x"}, testNode{Name: "n", Next: &testNode{Name: "m"}},
+	}
+
+	approach := NewMinDataApproach()
+	sz := approach.(Sizer)
+
+	for i, test := range tests {
+		b, err := approach.Pack(test)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+
+		size, err := sz.SizeOf(test)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if size != len(b) {
+			t.Fatalf("(%d) Size mismatch for %T: expected: %d, got: %d", i, test, len(b), size)
+		}
+
+		size, err = SizeOf(test, WithSerialisationApproach(testNoSizer{approach}))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if size != len(b) {
+			t.Fatalf("(%d) Size mismatch for %T without Sizer: expected: %d, got: %d", i, test, len(b), size)
+		}
+	}
+
+	if _, err := SizeOf(make(chan int)); err != ErrMinDataTypeNotSerialisable {
+		t.Fatalf("Expected ErrMinDataTypeNotSerialisable, got: %v", err)
+	}
+
+	// Natively supported types are sized without allocation
+	v := []any{[]float64{1, 2, 3}, []string{"a", "b"}, tm}
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, vv := range v {
+			if _, err := sz.SizeOf(vv); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Fatalf("Expected no allocations, got: %v", allocs)
+	}
+
+	// ToBytesMany output is unaffected by whether the Approach implements Sizer
+	expected, _, err := ToBytesMany(tests, WithSerialisationApproach(testNoSizer{approach}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, n := range []int{0, 4} {
+		b, _, err := ToBytesMany(tests, WithConcurrency(n))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !bytes.Equal(b, expected) {
+			t.Fatalf("(%d) Unexpected variation in byte slices", n)
+		}
+	}
+
+	// ToBytesMany serialises each item once, whether or not its size can be calculated directly
+	for version := V1; version < OutOfRange; version++ {
+		c := &testCountingSizer{Approach: NewMinDataApproachWithVersion(version)}
+
+		// Only natively supported types in V1 can be sized without serialising
+		for i, test := range tests {
+			_, builtin := builtinTypeIDs[reflect.TypeOf(test)]
+			if _, ok, _ := c.quickSizeOf(test); ok != (version == V1 && (builtin || test == nil)) {
+				t.Fatalf("(%v, %d) Unexpected quick size of %T: %v", version, i, test, ok)
+			}
+		}
+
+		b, _, err := ToBytesMany(tests, WithSerialisationApproach(c))
+		if err != nil {
+			t.Fatalf("(%v) Unexpected error: %v", version, err)
+		}
+		if n := c.packs.Load(); n != int64(len(tests)) {
+			t.Fatalf("(%v) Expected %d serialisations, got: %d", version, len(tests), n)
+		}
+
+		expected, _, err := ToBytesMany(tests, WithSerialisationApproach(testNoSizer{c.Approach}))
+		if err != nil {
+			t.Fatalf("(%v) Unexpected error: %v", version, err)
+		}
+		if !bytes.Equal(b, expected) {
+			t.Fatalf("(%v) Unexpected variation in byte slices", version)
+		}
+	}
+}
@@ -0,0 +1,726 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// builtinTypeIDs maps the types that are natively supported by MinData to their TypeID
+var builtinTypeIDs = map[reflect.Type]TypeID{
+	reflect.TypeFor[int8]():            Int8Type,
+	reflect.TypeFor[*int8]():           Pint8Type,
+	reflect.TypeFor[[]int8]():          Int8SliceType,
+	reflect.TypeFor[int16]():           Int16Type,
+	reflect.TypeFor[*int16]():          Pint16Type,
+	reflect.TypeFor[[]int16]():         Int16SliceType,
+	reflect.TypeFor[int32]():           Int32Type,
+	reflect.TypeFor[*int32]():          Pint32Type,
+	reflect.TypeFor[[]int32]():         Int32SliceType,
+	reflect.TypeFor[int64]():           Int64Type,
+	reflect.TypeFor[*int64]():          Pint64Type,
+	reflect.TypeFor[[]int64]():         Int64SliceType,
+	reflect.TypeFor[uint8]():           Uint8Type,
+	reflect.TypeFor[*uint8]():          Puint8Type,
+	reflect.TypeFor[uint16]():          Uint16Type,
+	reflect.TypeFor[*uint16]():         Puint16Type,
+	reflect.TypeFor[[]uint16]():        Uint16SliceType,
+	reflect.TypeFor[uint32]():          Uint32Type,
+	reflect.TypeFor[*uint32]():         Puint32Type,
+	reflect.TypeFor[[]uint32]():        Uint32SliceType,
+	reflect.TypeFor[uint64]():          Uint64Type,
+	reflect.TypeFor[*uint64]():         Puint64Type,
+	reflect.TypeFor[[]uint64]():        Uint64SliceType,
+	reflect.TypeFor[float32]():         Float32Type,
+	reflect.TypeFor[*float32]():        Pfloat32Type,
+	reflect.TypeFor[[]float32]():       Float32SliceType,
+	reflect.TypeFor[float64]():         Float64Type,
+	reflect.TypeFor[*float64]():        Pfloat64Type,
+	reflect.TypeFor[[]float64]():       Float64SliceType,
+	reflect.TypeFor[bool]():            BoolType,
+	reflect.TypeFor[*bool]():           PboolType,
+	reflect.TypeFor[[]bool]():          BoolSliceType,
+	reflect.TypeFor[time.Duration]():   DurationType,
+	reflect.TypeFor[*time.Duration]():  PdurationType,
+	reflect.TypeFor[[]time.Duration](): DurationSliceType,
+	reflect.TypeFor[string]():          StringType,
+	reflect.TypeFor[*string]():         PstringType,
+	reflect.TypeFor[[]string]():        StringSliceType,
+	reflect.TypeFor[time.Time]():       TimeType,
+	reflect.TypeFor[*time.Time]():      PtimeType,
+	reflect.TypeFor[[]byte]():          ByteSliceType,
+	reflect.TypeFor[[][]byte]():        ByteSliceSliceType,
+	reflect.TypeFor[*BitSet]():         BitSetType,
+}
+
+// builtinTypes is the reverse of builtinTypeIDs
+var builtinTypes = func() map[TypeID]reflect.Type {
+	m := make(map[TypeID]reflect.Type, len(builtinTypeIDs))
+	for t, id := range builtinTypeIDs {
+		m[id] = t
+	}
+	return m
+}()
+
+// packReflect serialises types that are not handled directly by the type switch within Pack
+func (m *minData) packReflect(data any) ([]byte, error) {
+	t := reflect.TypeOf(data)
+
+	if t.Kind() == reflect.Pointer && reflect.ValueOf(data).IsNil() {
+		return m.packTypeMD([]byte{byte(TypedNilType)}, t)
+	}
+
+	if c, ok := codecForType(t); ok {
+		b, err := c.pack(data)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{byte(c.id)}, b...), nil
+	}
+
+	if _, ok := marshalerTypeIDMD(t); ok {
+		if _, ok := registeredName(t); ok {
+			return m.packMarshalerMD(data)
+		}
+	}
+
+	if u, ok := underlyingTypeMD(t); ok {
+		return m.packNamedMD(t, reflect.ValueOf(data).Convert(u).Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return m.packSliceMD(reflect.ValueOf(data))
+	case reflect.Map:
+		return m.packMapMD(reflect.ValueOf(data))
+	case reflect.Struct:
+		return m.packStructMD(reflect.ValueOf(data))
+	case reflect.Pointer:
+		return m.packPointerMD(reflect.ValueOf(data))
+	default:
+		return nil, ErrMinDataTypeNotSerialisable
+	}
+}
+
+// packStructMD serialises an instance of a registered struct type as its name
+// followed by the name and value of each exported field.  Values are serialised
+// individually by Pack, so that interface-typed fields record their concrete type.
+func (m *minData) packStructMD(v reflect.Value) ([]byte, error) {
+	name, ok := structNameMD(v.Type())
+	if !ok {
+		return nil, ErrMinDataTypeNotSerialisable
+	}
+
+	fields := make([]int, 0, v.NumField())
+	for i := range v.NumField() {
+		if v.Type().Field(i).IsExported() {
+			fields = append(fields, i)
+		}
+	}
+
+	b := m.appendNameMD([]byte{byte(StructType)}, name)
+	b = appendLenMD(b, len(fields), m.compact())
+
+	var err error
+	for _, i := range fields {
+		b = m.appendNameMD(b, v.Type().Field(i).Name)
+		if b, err = m.appendItemMD(b, v.Field(i)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// structNameMD returns the name registered for the struct type, which may have
+// been registered using either an instance of the struct or a pointer to an instance
+func structNameMD(t reflect.Type) (string, bool) {
+	if name, ok := registeredName(t); ok {
+		return name, true
+	}
+	return registeredName(reflect.PointerTo(t))
+}
+
+// unpackStructMD deserialises an instance of a struct created by packStructMD.
+// Fields that are no longer present within the struct type are ignored.
+func (m *minData) unpackStructMD(data []byte) (any, error) {
+	name, n := m.readNameMD(data)
+	typ, ok := registeredType(name)
+	if !ok {
+		return nil, ErrUnknownTypeName
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	data = data[n:]
+
+	size, n := readLenMD(data, m.compact())
+	data = data[n:]
+
+	v := reflect.New(typ).Elem()
+	for range size {
+		fname, n := m.readNameMD(data)
+		data = data[n:]
+
+		f := v.FieldByName(fname)
+		if !f.IsValid() || !f.CanSet() {
+			f = reflect.New(reflect.TypeFor[any]()).Elem()
+		}
+
+		var err error
+		if data, err = m.unpackItemMD(data, f); err != nil {
+			return nil, err
+		}
+	}
+	return v.Interface(), nil
+}
+
+// packPointerMD serialises a non-nil pointer as the type of its element followed by the element
+func (m *minData) packPointerMD(v reflect.Value) ([]byte, error) {
+	b, err := m.packTypeMD([]byte{byte(PointerType)}, v.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+	return m.appendItemMD(b, v.Elem())
+}
+
+// unpackPointerMD deserialises a pointer created by packPointerMD
+func (m *minData) unpackPointerMD(data []byte) (any, error) {
+	p, data, err := m.newPointerMD(data)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = m.unpackItemMD(data, p.Elem()); err != nil {
+		return nil, err
+	}
+	return p.Interface(), nil
+}
+
+// newPointerMD allocates the pointer described by data created by packPointerMD,
+// returning the remaining data which holds the value of the pointer's element
+func (m *minData) newPointerMD(data []byte) (reflect.Value, []byte, error) {
+	elem, n, err := m.unpackTypeMD(data)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return reflect.New(elem), data[n:], nil
+}
+
+// unpackTypedNilMD returns a nil pointer of the type described within the data
+func (m *minData) unpackTypedNilMD(data []byte) (any, error) {
+	typ, n, err := m.unpackTypeMD(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) || typ.Kind() != reflect.Pointer {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	return reflect.Zero(typ).Interface(), nil
+}
+
+// packNamedMD serialises an instance of a named type using its underlying value.
+// If the named type is registered then its name is recorded ahead of the underlying
+// value so that Unpack returns the named type; otherwise the underlying value is
+// serialised directly, and so Unpack will return an instance of the underlying type.
+func (m *minData) packNamedMD(t reflect.Type, underlying any) ([]byte, error) {
+	b, err := m.packMD(underlying)
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := registeredName(t)
+	if !ok {
+		return b, nil
+	}
+
+	return append(m.appendNameMD([]byte{byte(NamedType)}, name), b...), nil
+}
+
+// underlyingTypeMD returns the unnamed type that has the same underlying type as t,
+// when t is a named type whose underlying type can be serialised
+func underlyingTypeMD(t reflect.Type) (reflect.Type, bool) {
+	var u reflect.Type
+	switch t.Kind() {
+	case reflect.Int8:
+		u = reflect.TypeFor[int8]()
+	case reflect.Int16:
+		u = reflect.TypeFor[int16]()
+	case reflect.Int32:
+		u = reflect.TypeFor[int32]()
+	case reflect.Int64:
+		u = reflect.TypeFor[int64]()
+	case reflect.Uint8:
+		u = reflect.TypeFor[uint8]()
+	case reflect.Uint16:
+		u = reflect.TypeFor[uint16]()
+	case reflect.Uint32:
+		u = reflect.TypeFor[uint32]()
+	case reflect.Uint64:
+		u = reflect.TypeFor[uint64]()
+	case reflect.Float32:
+		u = reflect.TypeFor[float32]()
+	case reflect.Float64:
+		u = reflect.TypeFor[float64]()
+	case reflect.Bool:
+		u = reflect.TypeFor[bool]()
+	case reflect.String:
+		u = reflect.TypeFor[string]()
+	case reflect.Slice:
+		u = reflect.SliceOf(t.Elem())
+	case reflect.Map:
+		u = reflect.MapOf(t.Key(), t.Elem())
+	default:
+		return nil, false
+	}
+	return u, u != t
+}
+
+// unpackNamedMD deserialises an instance of a named type created by packNamedMD
+func (m *minData) unpackNamedMD(data []byte) (any, error) {
+	name, n := m.readNameMD(data)
+	typ, ok := registeredType(name)
+	if !ok {
+		return nil, ErrUnknownTypeName
+	}
+
+	v, err := m.unpackMD(data[n:])
+	if err != nil {
+		return nil, err
+	}
+
+	rv := reflect.ValueOf(v)
+	if v == nil || !rv.Type().ConvertibleTo(typ) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+	return rv.Convert(typ).Interface(), nil
+}
+
+// marshalerTypeIDMD returns the TypeID used to serialise instances of t,
+// if t implements encoding.BinaryMarshaler or encoding.TextMarshaler
+func marshalerTypeIDMD(t reflect.Type) (TypeID, bool) {
+	switch {
+	case t.Implements(reflect.TypeFor[encoding.BinaryMarshaler]()):
+		return BinaryMarshalerType, true
+	case t.Implements(reflect.TypeFor[encoding.TextMarshaler]()):
+		return TextMarshalerType, true
+	default:
+		return UnknownType, false
+	}
+}
+
+// errNotUnmarshaler is raised by newFromMarshaled if the type does not implement the
+// unmarshal method matching its marshal method; Approaches replace it with their own error
+var errNotUnmarshaler = errors.New("type does not implement the matching unmarshal method")
+
+// newFromMarshaled returns a new instance of t, a registered type, unmarshaled from b
+// using encoding.BinaryUnmarshaler if id is BinaryMarshalerType, otherwise using
+// encoding.TextUnmarshaler.  b may be retained by the instance.
+func newFromMarshaled(t reflect.Type, id TypeID, b []byte) (reflect.Value, error) {
+	// Unmarshal methods are generally declared on the pointer, so always
+	// unmarshal into a pointer and dereference if a value was registered
+	var p reflect.Value
+	if t.Kind() == reflect.Pointer {
+		p = reflect.New(t.Elem())
+	} else {
+		p = reflect.New(t)
+	}
+
+	var err error
+	switch id {
+	case BinaryMarshalerType:
+		u, ok := p.Interface().(encoding.BinaryUnmarshaler)
+		if !ok {
+			return reflect.Value{}, errNotUnmarshaler
+		}
+		err = u.UnmarshalBinary(b)
+	default:
+		u, ok := p.Interface().(encoding.TextUnmarshaler)
+		if !ok {
+			return reflect.Value{}, errNotUnmarshaler
+		}
+		err = u.UnmarshalText(b)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if t.Kind() == reflect.Pointer {
+		return p, nil
+	}
+	return p.Elem(), nil
+}
+
+// appendNameMD appends the name to b, prefixed by its length
+func (m *minData) appendNameMD(b []byte, name string) []byte {
+	b = appendLenMD(b, len(name), m.compact())
+	return append(b, name...)
+}
+
+// readNameMD reads a name created by appendNameMD, returning the name and the number of bytes read
+func (m *minData) readNameMD(data []byte) (string, int) {
+	size, n := readLenMD(data, m.compact())
+	return string(data[n : n+size]), n + size
+}
+
+// packSliceMD serialises a slice as its element type followed by each element,
+// with each element serialised individually by Pack
+func (m *minData) packSliceMD(v reflect.Value) ([]byte, error) {
+	b, err := m.packTypeMD([]byte{byte(SliceType)}, v.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	b = appendLenMD(b, v.Len(), m.compact())
+	for i := range v.Len() {
+		if b, err = m.appendItemMD(b, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// packMapMD serialises a map as its key and element types followed by each entry.
+// Entries are sorted by their serialised key so that the output is deterministic.
+// When references are tracked, reference ids are assigned in the sorted order, so
+// that each reference is deserialised before any back-reference to it.
+func (m *minData) packMapMD(v reflect.Value) ([]byte, error) {
+	b, err := m.packTypeMD([]byte{byte(MapType)}, v.Type().Key())
+	if err != nil {
+		return nil, err
+	}
+	b, err = m.packTypeMD(b, v.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are sorted using state that is discarded, so that no reference ids are assigned
+	km := m
+	if m.trackRefs && m.refs != nil {
+		km = m.withRefs()
+		km.refs.depth = m.refs.depth
+	}
+
+	type entryMD struct {
+		key        []byte
+		keyV, valV reflect.Value
+	}
+
+	entries := make([]entryMD, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := km.appendItemMD(nil, iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entryMD{key: k, keyV: iter.Key(), valV: iter.Value()})
+	}
+	slices.SortFunc(entries, func(x, y entryMD) int {
+		return bytes.Compare(x.key, y.key)
+	})
+
+	b = appendLenMD(b, len(entries), m.compact())
+	for _, e := range entries {
+		if km != m {
+			if b, err = m.appendItemMD(b, e.keyV); err != nil {
+				return nil, err
+			}
+		} else {
+			b = append(b, e.key...)
+		}
+		if b, err = m.appendItemMD(b, e.valV); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// appendItemMD appends the serialised value, prefixed by its length
+func (m *minData) appendItemMD(b []byte, v reflect.Value) ([]byte, error) {
+	ib, err := m.packMD(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	b = appendLenMD(b, len(ib), m.compact())
+	return append(b, ib...), nil
+}
+
+// packTypeMD appends a description of the type to b, so that
+// instances of the type can be created during deserialisation
+func (m *minData) packTypeMD(b []byte, t reflect.Type) ([]byte, error) {
+	if id, ok := builtinTypeIDs[t]; ok {
+		return append(b, byte(id)), nil
+	}
+
+	if c, ok := codecForType(t); ok {
+		return append(b, byte(c.id)), nil
+	}
+
+	if name, ok := registeredName(t); ok {
+		if id, ok := marshalerTypeIDMD(t); ok {
+			return m.appendNameMD(append(b, byte(id)), name), nil
+		}
+		if _, ok := underlyingTypeMD(t); ok {
+			return m.appendNameMD(append(b, byte(NamedType)), name), nil
+		}
+	}
+
+	// Unregistered named types are serialised as their underlying type
+	if u, ok := underlyingTypeMD(t); ok {
+		return m.packTypeMD(b, u)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		name, ok := structNameMD(t)
+		if !ok {
+			return nil, ErrMinDataTypeNotSerialisable
+		}
+		return m.appendNameMD(append(b, byte(StructType)), name), nil
+	case reflect.Pointer:
+		return m.packTypeMD(append(b, byte(PointerType)), t.Elem())
+	case reflect.Interface:
+		// The empty interface is recorded with an empty name
+		if t == reflect.TypeFor[any]() {
+			return m.appendNameMD(append(b, byte(InterfaceType)), ""), nil
+		}
+		name, ok := registeredName(t)
+		if !ok {
+			return nil, ErrMinDataTypeNotSerialisable
+		}
+		return m.appendNameMD(append(b, byte(InterfaceType)), name), nil
+	case reflect.Slice:
+		return m.packTypeMD(append(b, byte(SliceType)), t.Elem())
+	case reflect.Map:
+		b, err := m.packTypeMD(append(b, byte(MapType)), t.Key())
+		if err != nil {
+			return nil, err
+		}
+		return m.packTypeMD(b, t.Elem())
+	default:
+		return nil, ErrMinDataTypeNotSerialisable
+	}
+}
+
+// unpackTypeMD reads a type description created by packTypeMD, returning
+// the type and the number of bytes read
+func (m *minData) unpackTypeMD(data []byte) (reflect.Type, int, error) {
+	t := TypeID(data[0])
+
+	if typ, ok := builtinTypes[t]; ok {
+		return typ, 1, nil
+	}
+
+	switch t {
+	case BinaryMarshalerType, TextMarshalerType, NamedType:
+		name, n := m.readNameMD(data[1:])
+		typ, ok := registeredType(name)
+		if !ok {
+			return nil, 0, ErrUnknownTypeName
+		}
+		return typ, 1 + n, nil
+	case StructType:
+		name, n := m.readNameMD(data[1:])
+		typ, ok := registeredType(name)
+		if !ok {
+			return nil, 0, ErrUnknownTypeName
+		}
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+		return typ, 1 + n, nil
+	case InterfaceType:
+		name, n := m.readNameMD(data[1:])
+		if len(name) == 0 {
+			return reflect.TypeFor[any](), 1 + n, nil
+		}
+		typ, ok := registeredType(name)
+		if !ok {
+			return nil, 0, ErrUnknownTypeName
+		}
+		return typ, 1 + n, nil
+	case PointerType:
+		elem, n, err := m.unpackTypeMD(data[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return reflect.PointerTo(elem), 1 + n, nil
+	case SliceType:
+		elem, n, err := m.unpackTypeMD(data[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return reflect.SliceOf(elem), 1 + n, nil
+	case MapType:
+		key, n, err := m.unpackTypeMD(data[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		elem, n2, err := m.unpackTypeMD(data[1+n:])
+		if err != nil {
+			return nil, 0, err
+		}
+		return reflect.MapOf(key, elem), 1 + n + n2, nil
+	default:
+		if c, ok := codecForID(t); ok {
+			return c.t, 1, nil
+		}
+		return nil, 0, ErrMinDataTypeNotDeserialisable
+	}
+}
+
+// unpackUserTypeMD deserialises an instance using the codec registered with RegisterType
+func unpackUserTypeMD(t TypeID, data []byte) (any, error) {
+	c, ok := codecForID(t)
+	if !ok {
+		return nil, ErrMinDataTypeNotDeserialisable
+	}
+	return c.unpack(data)
+}
+
+// unpackSliceMD deserialises a slice created by packSliceMD
+func (m *minData) unpackSliceMD(data []byte) (any, error) {
+	elem, n, err := m.unpackTypeMD(data)
+	if err != nil {
+		return nil, err
+	}
+	data = data[n:]
+
+	size, n := readLenMD(data, m.compact())
+	data = data[n:]
+	if size > len(data)/lenWidthMD(m.compact()) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := reflect.MakeSlice(reflect.SliceOf(elem), size, size)
+	for i := range size {
+		if data, err = m.unpackItemMD(data, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	return v.Interface(), nil
+}
+
+// unpackMapMD deserialises a map created by packMapMD
+func (m *minData) unpackMapMD(data []byte) (any, error) {
+	key, n, err := m.unpackTypeMD(data)
+	if err != nil {
+		return nil, err
+	}
+	data = data[n:]
+
+	elem, n, err := m.unpackTypeMD(data)
+	if err != nil {
+		return nil, err
+	}
+	data = data[n:]
+
+	size, n := readLenMD(data, m.compact())
+	data = data[n:]
+	if size > len(data)/(2*lenWidthMD(m.compact())) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := reflect.MakeMapWithSize(reflect.MapOf(key, elem), size)
+	for range size {
+		k := reflect.New(key).Elem()
+		if data, err = m.unpackItemMD(data, k); err != nil {
+			return nil, err
+		}
+		e := reflect.New(elem).Elem()
+		if data, err = m.unpackItemMD(data, e); err != nil {
+			return nil, err
+		}
+		v.SetMapIndex(k, e)
+	}
+	return v.Interface(), nil
+}
+
+// unpackItemMD deserialises a length prefixed value created by appendItemMD into dst,
+// returning the remaining data
+func (m *minData) unpackItemMD(data []byte, dst reflect.Value) ([]byte, error) {
+	size, n := readLenMD(data, m.compact())
+	v, err := m.unpackMD(data[n : n+size])
+	if err != nil {
+		return nil, err
+	}
+	if err = setValueMD(dst, v); err != nil {
+		return nil, err
+	}
+	return data[n+size:], nil
+}
+
+// setValueMD assigns the deserialised value to dst, ensuring the types are compatible
+func setValueMD(dst reflect.Value, v any) error {
+	if v == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			return nil
+		default:
+			return ErrUnexpectedDeserialisationError
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(dst.Type()) {
+		return ErrUnexpectedDeserialisationError
+	}
+	dst.Set(rv)
+	return nil
+}
+
+// Types implementing encoding.BinaryMarshaler or encoding.TextMarshaler are
+// recorded as their registered name followed by their marshalled bytes
+func (m *minData) packMarshalerMD(data any) ([]byte, error) {
+	name, ok := registeredName(reflect.TypeOf(data))
+	if !ok {
+		return nil, ErrMinDataTypeNotSerialisable
+	}
+
+	var t TypeID
+	var b []byte
+	var err error
+	switch v := data.(type) {
+	case encoding.BinaryMarshaler:
+		t = BinaryMarshalerType
+		b, err = v.MarshalBinary()
+	case encoding.TextMarshaler:
+		t = TextMarshalerType
+		b, err = v.MarshalText()
+	default:
+		return nil, ErrMinDataTypeNotSerialisable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return appendByteSliceSliceMD(nil, t, [][]byte{[]byte(name), b}, m.compact()), nil
+}
+
+func (m *minData) unpackMarshalerMD(t TypeID, data []byte) (any, error) {
+	bss, err := unpackByteSliceSliceMD(data, m.compact())
+	if err != nil {
+		return nil, err
+	}
+	if len(bss) != 2 {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	typ, ok := registeredType(string(bss[0]))
+	if !ok {
+		return nil, ErrUnknownTypeName
+	}
+
+	v, err := newFromMarshaled(typ, t, bytes.Clone(bss[1]))
+	if err == errNotUnmarshaler {
+		return nil, ErrMinDataTypeNotDeserialisable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,173 @@
+package serialise
+
+import (
+	"encoding/binary"
+	"math"
+	"slices"
+	"unsafe"
+)
+
+// versionHeaderMD is combined with the version to form the first byte of data serialised
+// by MinData from V2 onwards.  As TypeIDs are never negative, the header cannot be
+// mistaken for the TypeID that begins data serialised by V1.
+const versionHeaderMD byte = 0x80
+
+// compact is true for versions that use variable length encodings of
+// lengths and integers, so that small values serialise small
+func (m *minData) compact() bool {
+	return m.version >= V2
+}
+
+// appendLenMD appends a length or count, either as an unsigned varint or
+// as a fixed width little endian value
+func appendLenMD(b []byte, n int, compact bool) []byte {
+	if compact {
+		return binary.AppendUvarint(b, uint64(n))
+	}
+	return binary.LittleEndian.AppendUint64(b, uint64(n))
+}
+
+// readLenMD reads a length created by appendLenMD, returning the length and the number of
+// bytes read.  Invalid lengths panic, which is recovered by Unpack as a deserialisation error.
+func readLenMD(data []byte, compact bool) (int, int) {
+	if compact {
+		v, n := binary.Uvarint(data)
+		if n <= 0 || v > math.MaxInt {
+			panic(ErrUnexpectedDeserialisationError)
+		}
+		return int(v), n
+	}
+	return int(binary.LittleEndian.Uint64(data)), 8
+}
+
+// lenWidthMD is the minimum number of bytes used to record a length
+func lenWidthMD(compact bool) int {
+	if compact {
+		return 1
+	}
+	return 8
+}
+
+// varintMD are the integer types that are serialised as varints by compact versions
+type varintMD interface {
+	~int16 | ~int32 | ~int64 | ~uint16 | ~uint32 | ~uint64
+}
+
+// toUvarintMD returns the value to be written as an unsigned varint, with signed
+// values zigzag encoded so that small negative values are also small
+func toUvarintMD[T varintMD](v T) uint64 {
+	if ^T(0) < 0 {
+		x := int64(v)
+		return uint64(x<<1) ^ uint64(x>>63)
+	}
+	return uint64(v)
+}
+
+// fromUvarintMD reverses toUvarintMD, returning false if the value overflows T
+func fromUvarintMD[T varintMD](u uint64) (T, bool) {
+	if ^T(0) < 0 {
+		x := int64(u>>1) ^ -int64(u&1)
+		return T(x), int64(T(x)) == x
+	}
+	return T(u), uint64(T(u)) == u
+}
+
+// appendIntMD appends an integer as a varint, or as a fixed width value for V1
+func appendIntMD[T varintMD](b []byte, v T, compact bool) []byte {
+	if compact {
+		return binary.AppendUvarint(b, toUvarintMD(v))
+	}
+	return appendFixedMD(b, v)
+}
+
+// readIntMD reads an integer created by appendIntMD
+func readIntMD[T varintMD](data []byte, compact bool) (T, error) {
+	if compact {
+		v, n := readVarintValueMD[T](data)
+		if n <= 0 {
+			return 0, ErrUnexpectedDeserialisationError
+		}
+		return v, nil
+	}
+	return readFixedMD[T](data), nil
+}
+
+// ptrOrErrMD returns a pointer to v, or the error if one occurred
+func ptrOrErrMD[T any](v T, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// appendIntSliceMD appends a slice of integers, using the encoding of the version
+func appendIntSliceMD[T varintMD](b []byte, t TypeID, data []T, version MinDataVersion) []byte {
+	switch {
+	case version < V2:
+		return appendSimpleSliceMD(b, t, data, false)
+	case version < V3:
+		return appendVarintsMD(append(b, byte(t)), data)
+	default:
+		return appendIntColumnMD(append(b, byte(t)), data)
+	}
+}
+
+// unpackIntSliceMD deserialises a slice created by appendIntSliceMD
+func unpackIntSliceMD[T varintMD](data []byte, version MinDataVersion) (any, error) {
+	switch {
+	case version < V2:
+		return readSimpleSliceMD[T](data, int64(unsafe.Sizeof(T(0))), false)
+	case version < V3:
+		return readVarintsMD[T](data)
+	default:
+		return unpackIntColumnMD[T](data)
+	}
+}
+
+// appendVarintsMD appends the count of the values followed by each value as a varint
+func appendVarintsMD[T varintMD](b []byte, data []T) []byte {
+	b = slices.Grow(b, binary.MaxVarintLen64+len(data))
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	for _, v := range data {
+		b = binary.AppendUvarint(b, toUvarintMD(v))
+	}
+	return b
+}
+
+// readVarintsMD reads values created by appendVarintsMD
+func readVarintsMD[T varintMD](data []byte) (any, error) {
+	size, n := readLenMD(data, true)
+	data = data[n:]
+	if size > len(data) {
+		return nil, ErrUnexpectedDeserialisationError
+	}
+
+	v := make([]T, size)
+	for i := range v {
+		var n int
+		if v[i], n = readVarintValueMD[T](data); n <= 0 {
+			return nil, ErrUnexpectedDeserialisationError
+		}
+		data = data[n:]
+	}
+	return v, nil
+}
+
+// appendVarintValueMD appends a single value as a varint
+func appendVarintValueMD[T varintMD](b []byte, v T) []byte {
+	return binary.AppendUvarint(b, toUvarintMD(v))
+}
+
+// readVarintValueMD reads a value created by appendVarintValueMD, returning
+// the value and the number of bytes read, which is not positive if the data is invalid
+func readVarintValueMD[T varintMD](data []byte) (T, int) {
+	u, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, n
+	}
+	v, ok := fromUvarintMD[T](u)
+	if !ok {
+		return 0, -1
+	}
+	return v, n
+}
@@ -0,0 +1,165 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMinDataV2(t *testing.T) {
+
+	if err := Register("testNode", testNode{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	i16 := int16(math.MinInt16)
+	u64 := uint64(math.MaxUint64)
+	d := -time.Millisecond
+	tm := time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC)
+	s := "Hello World"
+
+	tests := []any{
+		int8(-1), uint8(255), int16(-300), int32(math.MaxInt32), int64(math.MinInt64), int64(0),
+		uint16(math.MaxUint16), uint32(7), uint64(math.MaxUint64), float32(-1.5), math.Inf(1), true,
+		time.Hour, tm, s, []byte("abc"), &i16, &u64, &d, &s, (*int32)(nil),
+		[]int8{-1, 0, 1}, []int16{math.MinInt16, 0, math.MaxInt16}, []int32{-1, 1}, []int64{1, -2, 3, math.MaxInt64},
+		[]uint16{0, math.MaxUint16}, []uint32{1, 2}, []uint64{0, math.MaxUint64}, []float32{1.5},
+		[]float64{1, math.Pi}, []bool{true, false}, []time.Duration{time.Second, -time.Second},
+		[]string{"a", "", "bcd"}, [][]byte{[]byte("a"), {}}, []int64{},
+		map[string]int64{"a": -1, "b": 1 << 40}, []any{int16(-1), "x", nil},
+		testNode{Name: "n", Next: &testNode{Name: "m"}},
+	}
+
+	approach := NewMinDataApproachWithVersion(V2)
+	if approach.Name() != "MD2" {
+		t.Fatalf("Unexpected name: %s", approach.Name())
+	}
+
+	for i, test := range tests {
+		b, err := approach.Pack(test)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if b[0] != versionHeaderMD|byte(V2) {
+			t.Fatalf("(%d) Missing version header: %x", i, b[0])
+		}
+
+		v, err := approach.Unpack(b)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(v, test) {
+			t.Fatalf("(%d) Data mismatch: expected: %v (%T), got: %v (%T)", i, test, test, v, v)
+		}
+
+		size, err := approach.(Sizer).SizeOf(test)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if size != len(b) {
+			t.Fatalf("(%d) Size mismatch: expected: %d, got: %d", i, len(b), size)
+		}
+	}
+
+	// Reference tracking records reference ids as varints
+	node := &testNode{Name: "cycle"}
+	node.Next = node
+	tracking := NewMinDataApproachWithVersion(V2, WithReferenceTracking())
+	b, err := tracking.Pack(node)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := approach.Unpack(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := v.(*testNode); n.Next != n {
+		t.Fatal("Expected cyclic reference to be preserved")
+	}
+}
+
+func TestMinDataV2Format(t *testing.T) {
+
+	header := versionHeaderMD | byte(V2)
+
+	tests := []struct {
+		V      any
+		Expect []byte
+	}{
+		{int64(1), []byte{header, byte(Int64Type), 2}},
+		{int64(-1), []byte{header, byte(Int64Type), 1}},
+		{uint32(300), []byte{header, byte(Uint32Type), 0xac, 0x02}},
+		{int8(-1), []byte{header, byte(Int8Type), 0xff}},
+		{float32(1), []byte{header, byte(Float32Type), 0, 0, 0x80, 0x3f}},
+		{[]int64{1, -2, 3}, []byte{header, byte(Int64SliceType), 3, 2, 3, 6}},
+		{[]bool{true, false}, []byte{header, byte(BoolSliceType), 2, 1, 0}},
+		{"ab", []byte{header, byte(StringType), 'a', 'b'}},
+		{[]string{"a", "bc"}, []byte{header, byte(StringSliceType), 2, 1, 'a', 2, 'b', 'c'}},
+		{[][]byte(nil), []byte{header, byte(ByteSliceSliceType)}},
+		{nil, []byte{header, byte(NilType)}},
+	}
+
+	approach := NewMinDataApproachWithVersion(V2)
+
+	for i, test := range tests {
+		b, err := approach.Pack(test.V)
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+		if !bytes.Equal(b, test.Expect) {
+			t.Fatalf("(%d) Format mismatch for %T: expected: %x, got: %x", i, test.V, test.Expect, b)
+		}
+	}
+
+	// Small integers serialise small without compression
+	counters := make([]int64, 1000)
+	for i := range counters {
+		counters[i] = int64(i % 100)
+	}
+	b1, err := NewMinDataApproachWithVersion(V1).Pack(counters)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b2, err := approach.Pack(counters)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(b2) > len(b1)/4 {
+		t.Fatalf("Expected compact encoding: V1: %d, V2: %d", len(b1), len(b2))
+	}
+}
+
+func TestMinDataV2InvalidData(t *testing.T) {
+
+	approach := NewMinDataApproachWithVersion(V2)
+	header := versionHeaderMD | byte(V2)
+
+	b3, err := NewMinDataApproachWithVersion(V3).Pack(int64(1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := approach.Unpack(b3); err != ErrMinDataVersionMismatch {
+		t.Fatalf("Expected ErrMinDataVersionMismatch, got: %v", err)
+	}
+
+	overflow := binary.AppendUvarint([]byte{header, byte(Int16Type)}, 1<<20)
+
+	tests := [][]byte{
+		{},
+		{header},
+		{header, byte(Int64Type), 0x80},
+		overflow,
+		{header, byte(Int64SliceType), 5, 2},
+		{header, byte(StringSliceType), 1, 9, 'a'},
+		{header, byte(Int64SliceType), 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+	}
+
+	for i, test := range tests {
+		if _, err := approach.Unpack(test); err != ErrUnexpectedDeserialisationError {
+			t.Fatalf("(%d) Expected ErrUnexpectedDeserialisationError, got: %v", i, err)
+		}
+	}
+}
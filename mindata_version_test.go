@@ -0,0 +1,144 @@
+package serialise
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMinDataReadsEarlierVersions(t *testing.T) {
+
+	tm := time.Date(2024, 2, 29, 12, 30, 0, 123, time.UTC)
+
+	tests := []any{
+		nil, int8(-1), int16(-300), int64(1 << 40), uint64(42), 3.14, true, "Hello World", []byte("abc"),
+		[]int64{1, 2, 3, 4, 5}, []float64{1.5, 1.5, 1.5}, []bool{true, false, true}, tm,
+		[]string{"a", "b", "a", "a"}, map[string]int64{"a": 1}, []any{int8(1), "x"},
+	}
+
+	for from := V1; from < OutOfRange; from++ {
+		writer := NewMinDataApproachWithVersion(from)
+
+		for to := from; to < OutOfRange; to++ {
+			reader := NewMinDataApproachWithVersion(to)
+
+			for i, test := range tests {
+				b, err := writer.Pack(test)
+				if err != nil {
+					t.Fatalf("(%d, %d, %d) Unexpected error: %v", from, to, i, err)
+				}
+				v, err := reader.Unpack(b)
+				if err != nil {
+					t.Fatalf("(%d, %d, %d) Unexpected error: %v", from, to, i, err)
+				}
+				if !reflect.DeepEqual(v, test) {
+					t.Fatalf("(%d, %d, %d) Data mismatch: expected %v, got: %v", from, to, i, test, v)
+				}
+			}
+		}
+
+		// Data serialised by later versions cannot be read
+		if from > V1 {
+			b, err := writer.Pack(int64(1))
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", from, err)
+			}
+			if _, err := NewMinDataApproachWithVersion(from - 1).Unpack(b); err != ErrMinDataVersionMismatch {
+				t.Fatalf("(%d) Expected ErrMinDataVersionMismatch, got: %v", from, err)
+			}
+		}
+	}
+
+	// Options are retained when reading earlier versions
+	b, _, err := ToBytes([]bool{true, false, true}, WithSerialisationApproach(NewMinDataApproachWithVersion(V3)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := FromBytes(b, NewMinDataApproachWithVersion(V5), WithBoolsAsBitSet())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if bs, ok := v.(*BitSet); !ok || bs.Len() != 3 || !bs.Get(0) || bs.Get(1) || !bs.Get(2) {
+		t.Fatalf("Expected a *BitSet, got: %v", v)
+	}
+
+	approach := NewMinDataApproachWithVersion(V5)
+	for _, test := range [][]byte{{}, {versionHeaderMD | byte(V1)}, {versionHeaderMD}} {
+		if _, err := approach.Unpack(test); err != ErrUnexpectedDeserialisationError {
+			t.Fatalf("(%v) Expected ErrUnexpectedDeserialisationError, got: %v", test, err)
+		}
+	}
+	if _, err := approach.Unpack([]byte{versionHeaderMD | byte(OutOfRange), byte(Int8Type), 1}); err != ErrMinDataVersionMismatch {
+		t.Fatalf("Expected ErrMinDataVersionMismatch, got: %v", err)
+	}
+}
+
+func TestNewMinDataApproachForVersion(t *testing.T) {
+
+	for version := V1; version < OutOfRange; version++ {
+		a, err := NewMinDataApproachForVersion(version, WithReferenceTracking())
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", version, err)
+		}
+		if a.Name() != NewMinDataApproachWithVersion(version).Name() {
+			t.Fatalf("(%d) Name mismatch: got: %s", version, a.Name())
+		}
+	}
+
+	for _, version := range []MinDataVersion{UnknownVersion, OutOfRange, -1} {
+		if _, err := NewMinDataApproachForVersion(version); err != ErrInvalidMinDataVersion {
+			t.Fatalf("(%d) Expected ErrInvalidMinDataVersion, got: %v", version, err)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("Expected NewMinDataApproachWithVersion to panic")
+		}
+	}()
+	NewMinDataApproachWithVersion(OutOfRange)
+}
+
+func TestTranscode(t *testing.T) {
+
+	var key = []byte("01234567890123456789012345678912")
+
+	v1 := NewMinDataApproachWithVersion(V1)
+	latest, err := GetApproach(MinDataAlias)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data := []int64{100, 101, 102, 103, 104, 105}
+
+	b, _, err := ToBytes(data, WithSerialisationApproach(v1), WithAESGCMEncryption(key))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tb, name, err := Transcode(b, v1, latest, WithAESGCMEncryption(key))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != latest.Name() {
+		t.Fatalf("Expected %s, got: %s", latest.Name(), name)
+	}
+
+	// The transcoded data is no longer readable by V1
+	if _, err := FromBytes(tb, v1, WithAESGCMEncryption(key)); err != ErrMinDataVersionMismatch {
+		t.Fatalf("Expected ErrMinDataVersionMismatch, got: %v", err)
+	}
+
+	v, err := FromBytes(tb, latest, WithAESGCMEncryption(key))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCompareSliceValue[int64](v, data, "[]int64", t)
+
+	if _, _, err := Transcode(b, v1, nil); err != ErrInvalidSerialisationApproach {
+		t.Fatalf("Expected ErrInvalidSerialisationApproach, got: %v", err)
+	}
+	if _, _, err := Transcode(b, nil, latest); err != ErrInvalidSerialisationApproach {
+		t.Fatalf("Expected ErrInvalidSerialisationApproach, got: %v", err)
+	}
+}
@@ -2,47 +2,244 @@ package serialise
 
 import (
 	"errors"
+	"maps"
+	"slices"
 	"sync"
 )
 
-type serialisationRegistry struct {
-	m   map[string]Approach
-	lck sync.RWMutex
+// Registry holds Approaches by name, so that the Approach used to serialise data can be
+// retrieved to deserialise it.  The package functions such as RegisterApproach and GetApproach
+// use the global Registry returned by DefaultRegistry, whilst scoped instances created by
+// NewRegistry can be passed to ToBytes and FromBytesAuto using WithRegistry, for example
+// to use fake Approaches in tests or separate Approaches per tenant.
+type Registry struct {
+	m        map[string]Approach
+	aliases  map[string]string
+	fallback *Registry
+	lck      sync.RWMutex
 }
 
-var registry *serialisationRegistry
+// NewRegistry creates an empty Registry.  Names that are not registered are retrieved from
+// the fallback Registry if provided, so that NewRegistry(DefaultRegistry()) adds to or
+// overrides the Approaches of the global Registry without modifying it.
+func NewRegistry(fallback *Registry) *Registry {
+	return &Registry{
+		m:        map[string]Approach{},
+		aliases:  map[string]string{},
+		fallback: fallback,
+	}
+}
+
+var registry *Registry
+
+// DefaultRegistry returns the global Registry used by the package functions, and by
+// ToBytes and FromBytesAuto if no Registry is provided using WithRegistry
+func DefaultRegistry() *Registry {
+	return registry
+}
+
+// MinDataAlias is the alias of the latest version of MinData in the Approach registry
+const MinDataAlias = "MD"
 
 func init() {
-	registry = &serialisationRegistry{
-		m: map[string]Approach{},
-	}
+	registry = NewRegistry(nil)
 
 	RegisterApproach(NewMinDataApproachWithVersion(V1))
+	RegisterApproach(NewMinDataApproachWithVersion(V2))
+	RegisterApproach(NewMinDataApproachWithVersion(V3))
+	RegisterApproach(NewMinDataApproachWithVersion(V4))
+	RegisterApproach(NewMinDataApproachWithVersion(V5))
+	RegisterApproach(NewJSONApproach())
+	RegisterApproach(NewCBORApproach())
+
+	if err := RegisterApproachAlias(MinDataAlias, NewMinDataApproachWithVersion(OutOfRange-1).Name()); err != nil {
+		panic(err)
+	}
 }
 
-// RegisterApproach allows all registered Approach to be retrievable by Name()
+// ErrInvalidApproachRegistration raised if an Approach is registered without a name
+var ErrInvalidApproachRegistration = errors.New("a non-nil Approach with a name must be provided to register an Approach")
+
+// ErrApproachAlreadyRegistered raised if the name of an Approach or alias is already registered
+var ErrApproachAlreadyRegistered = errors.New("specified Approach name is already registered")
+
+// RegisterApproach allows all registered Approach to be retrievable by Name().
+// An Approach already registered with the same name is replaced; use
+// RegisterApproachUnique to prevent an Approach being replaced accidentally.
 func RegisterApproach(a Approach) {
-	if a == nil {
-		return
-	}
+	registry.Register(a)
+}
 
-	registry.lck.Lock()
-	defer registry.lck.Unlock()
+// RegisterApproachUnique registers the Approach so that it is retrievable by Name(),
+// returning ErrApproachAlreadyRegistered if an Approach or alias is already registered
+// with the same name.
+func RegisterApproachUnique(a Approach) error {
+	return registry.RegisterUnique(a)
+}
+
+// RegisterApproachAlias allows the registered Approach with the specified name to also be
+// retrieved using the alias, e.g. MinDataAlias ("MD") for the latest version of MinData.
+// An existing alias is repointed to the Approach, but ErrApproachAlreadyRegistered is
+// returned if the alias is the name of a registered Approach.
+func RegisterApproachAlias(alias, name string) error {
+	return registry.RegisterAlias(alias, name)
+}
+
+// UnregisterApproach removes the Approach or alias with the specified name from the registry,
+// returning ErrUnknownApproach if it is not registered.  Aliases of a removed Approach are
+// also removed.
+func UnregisterApproach(name string) error {
+	return registry.Unregister(name)
+}
 
-	registry.m[a.Name()] = a
+// ListApproaches returns the sorted names of the registered Approaches, excluding aliases
+func ListApproaches() []string {
+	return registry.List()
 }
 
 // ErrUnknownApproach raised if the specified name is not found in the Approach registry
 var ErrUnknownApproach = errors.New("specified Approach name is not registered")
 
-// GetApproach returns the Approach with the specified name
+// GetApproach returns the Approach with the specified name or alias
 func GetApproach(name string) (Approach, error) {
-	registry.lck.RLock()
-	defer registry.lck.RUnlock()
+	return registry.Get(name)
+}
+
+// Register allows the Approach to be retrievable by Name(), replacing
+// any Approach already registered in this Registry with the same name
+func (r *Registry) Register(a Approach) {
+	if a == nil {
+		return
+	}
+
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	r.m[a.Name()] = a
+}
+
+// RegisterUnique registers the Approach so that it is retrievable by Name(), returning
+// ErrApproachAlreadyRegistered if an Approach or alias is already registered in this
+// Registry with the same name.  Approaches in the fallback Registry may be overridden.
+func (r *Registry) RegisterUnique(a Approach) error {
+	if a == nil || len(a.Name()) == 0 {
+		return ErrInvalidApproachRegistration
+	}
+
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	name := a.Name()
+	if _, ok := r.m[name]; ok {
+		return ErrApproachAlreadyRegistered
+	}
+	if _, ok := r.aliases[name]; ok {
+		return ErrApproachAlreadyRegistered
+	}
+
+	r.m[name] = a
+	return nil
+}
+
+// RegisterAlias allows the Approach with the specified name, registered in this Registry
+// or its fallback, to also be retrieved using the alias.  The name cannot be an alias
+// registered in this Registry, so that aliases do not form cycles.  An existing alias is repointed
+// to the Approach, but ErrApproachAlreadyRegistered is returned if the alias is the name
+// of an Approach registered in this Registry.
+func (r *Registry) RegisterAlias(alias, name string) error {
+	if len(alias) == 0 {
+		return ErrInvalidApproachRegistration
+	}
+
+	// The name is resolved under the lock, so that the Approach cannot be
+	// unregistered from this Registry before the alias is added
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if _, ok := r.m[name]; !ok {
+		if r.fallback == nil {
+			return ErrUnknownApproach
+		}
+		if _, err := r.fallback.Get(name); err != nil {
+			return ErrUnknownApproach
+		}
+	}
+	if _, ok := r.m[alias]; ok {
+		return ErrApproachAlreadyRegistered
+	}
+
+	r.aliases[alias] = name
+	return nil
+}
+
+// Unregister removes the Approach or alias with the specified name from this Registry,
+// returning ErrUnknownApproach if it is not registered.  Aliases of a removed Approach
+// are also removed.  The fallback Registry is not modified.
+func (r *Registry) Unregister(name string) error {
+	r.lck.Lock()
+	defer r.lck.Unlock()
+
+	if _, ok := r.aliases[name]; ok {
+		delete(r.aliases, name)
+		return nil
+	}
+	if _, ok := r.m[name]; !ok {
+		return ErrUnknownApproach
+	}
+
+	delete(r.m, name)
+	maps.DeleteFunc(r.aliases, func(_, target string) bool {
+		return target == name
+	})
+	return nil
+}
+
+// List returns the sorted names of the Approaches registered in this Registry
+// and its fallback, excluding aliases
+func (r *Registry) List() []string {
+	r.lck.RLock()
+	names := slices.Collect(maps.Keys(r.m))
+	r.lck.RUnlock()
+
+	if r.fallback != nil {
+		names = append(names, r.fallback.List()...)
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Get returns the Approach with the specified name or alias, from this Registry
+// or its fallback
+func (r *Registry) Get(name string) (Approach, error) {
+	r.lck.RLock()
+	a, ok := r.m[name]
+	target, isAlias := r.aliases[name]
+	r.lck.RUnlock()
+
+	switch {
+	case ok:
+		return a, nil
+	case isAlias:
+		if a, ok := r.lookup(target); ok {
+			return a, nil
+		}
+	case r.fallback != nil:
+		return r.fallback.Get(name)
+	}
+	return nil, ErrUnknownApproach
+}
+
+// lookup returns the Approach registered with the name in this Registry,
+// or with the name or alias in its fallback
+func (r *Registry) lookup(name string) (Approach, bool) {
+	r.lck.RLock()
+	a, ok := r.m[name]
+	r.lck.RUnlock()
 
-	a, ok := registry.m[name]
-	if !ok {
-		return nil, ErrUnknownApproach
+	if !ok && r.fallback != nil {
+		fa, err := r.fallback.Get(name)
+		return fa, err == nil
 	}
-	return a, nil
+	return a, ok
 }
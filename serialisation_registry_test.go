@@ -0,0 +1,275 @@
+package serialise
+
+import (
+	"slices"
+	"testing"
+)
+
+// testNamedApproach renames the wrapped Approach
+type testNamedApproach struct {
+	Approach
+	name string
+}
+
+func (a testNamedApproach) Name() string {
+	return a.name
+}
+
+func TestListApproaches(t *testing.T) {
+
+	names := ListApproaches()
+	for _, name := range []string{"MD1", "MD2", "MD3", "MD4", "MD5"} {
+		if !slices.Contains(names, name) {
+			t.Fatalf("Expected %s to be registered, got: %v", name, names)
+		}
+	}
+	if slices.Contains(names, MinDataAlias) {
+		t.Fatalf("Expected aliases to be excluded, got: %v", names)
+	}
+	if !slices.IsSorted(names) {
+		t.Fatalf("Expected names to be sorted, got: %v", names)
+	}
+}
+
+func TestMinDataAlias(t *testing.T) {
+
+	a, err := GetApproach(MinDataAlias)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Name() != "MD5" {
+		t.Fatalf("Expected alias to resolve to the latest MinData, got: %s", a.Name())
+	}
+}
+
+func TestRegisterApproachUnique(t *testing.T) {
+
+	a := testNamedApproach{Approach: NewMinDataApproach(), name: "testRegisterUnique"}
+
+	if err := RegisterApproachUnique(nil); err != ErrInvalidApproachRegistration {
+		t.Fatalf("Expected ErrInvalidApproachRegistration, got: %v", err)
+	}
+	if err := RegisterApproachUnique(testNamedApproach{Approach: a}); err != ErrInvalidApproachRegistration {
+		t.Fatalf("Expected ErrInvalidApproachRegistration, got: %v", err)
+	}
+	if err := RegisterApproachUnique(a); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer UnregisterApproach(a.Name())
+
+	if err := RegisterApproachUnique(a); err != ErrApproachAlreadyRegistered {
+		t.Fatalf("Expected ErrApproachAlreadyRegistered, got: %v", err)
+	}
+	if err := RegisterApproachUnique(testNamedApproach{Approach: a, name: MinDataAlias}); err != ErrApproachAlreadyRegistered {
+		t.Fatalf("Expected ErrApproachAlreadyRegistered, got: %v", err)
+	}
+
+	got, err := GetApproach(a.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != Approach(a) {
+		t.Fatalf("Expected the registered Approach to be returned")
+	}
+}
+
+func TestUnregisterApproach(t *testing.T) {
+
+	a := testNamedApproach{Approach: NewMinDataApproach(), name: "testUnregister"}
+	RegisterApproach(a)
+
+	if err := RegisterApproachAlias("testUnregisterAlias", "testUnknown"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+	if err := RegisterApproachAlias("MD1", a.Name()); err != ErrApproachAlreadyRegistered {
+		t.Fatalf("Expected ErrApproachAlreadyRegistered, got: %v", err)
+	}
+	if err := RegisterApproachAlias("", a.Name()); err != ErrInvalidApproachRegistration {
+		t.Fatalf("Expected ErrInvalidApproachRegistration, got: %v", err)
+	}
+	for _, alias := range []string{"testUnregisterAlias", "testUnregisterAlias2"} {
+		if err := RegisterApproachAlias(alias, a.Name()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := GetApproach(alias); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	// Removing an alias leaves the Approach registered
+	if err := UnregisterApproach("testUnregisterAlias"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := GetApproach("testUnregisterAlias"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+	if _, err := GetApproach(a.Name()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Removing the Approach removes its remaining aliases
+	if err := UnregisterApproach(a.Name()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, name := range []string{a.Name(), "testUnregisterAlias2"} {
+		if _, err := GetApproach(name); err != ErrUnknownApproach {
+			t.Fatalf("(%s) Expected ErrUnknownApproach, got: %v", name, err)
+		}
+	}
+	if slices.Contains(ListApproaches(), a.Name()) {
+		t.Fatalf("Expected %s to be removed from the list", a.Name())
+	}
+
+	if err := UnregisterApproach(a.Name()); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+
+	fake := testNamedApproach{Approach: NewMinDataApproachWithVersion(V2), name: "MD1"}
+	extra := testNamedApproach{Approach: NewMinDataApproachWithVersion(V3), name: "testRegistryExtra"}
+
+	r := NewRegistry(DefaultRegistry())
+	if err := r.RegisterUnique(fake); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.RegisterUnique(extra); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := r.RegisterUnique(extra); err != ErrApproachAlreadyRegistered {
+		t.Fatalf("Expected ErrApproachAlreadyRegistered, got: %v", err)
+	}
+
+	// Scoped registrations override the fallback, without modifying it
+	a, err := r.Get("MD1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a != Approach(fake) {
+		t.Fatalf("Expected the scoped Approach to be returned")
+	}
+	if a, _ := GetApproach("MD1"); a == Approach(fake) {
+		t.Fatalf("Expected the global Registry to be unmodified")
+	}
+	if _, err := GetApproach(extra.Name()); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+
+	// Names and aliases are retrieved from the fallback
+	for _, name := range []string{"MD2", MinDataAlias} {
+		if _, err := r.Get(name); err != nil {
+			t.Fatalf("(%s) Unexpected error: %v", name, err)
+		}
+	}
+
+	names := r.List()
+	if !slices.Contains(names, extra.Name()) || !slices.Contains(names, "MD5") || !slices.IsSorted(names) {
+		t.Fatalf("Unexpected names: %v", names)
+	}
+	if len(slices.Compact(slices.Clone(names))) != len(names) {
+		t.Fatalf("Expected names to be unique, got: %v", names)
+	}
+
+	// Aliases may refer to Approaches in the fallback, and override aliases in the fallback
+	if err := r.RegisterAlias(MinDataAlias, extra.Name()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a, _ := r.Get(MinDataAlias); a != Approach(extra) {
+		t.Fatalf("Expected the scoped alias to be used")
+	}
+	if err := r.RegisterAlias("testRegistryAlias", "MD4"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a, _ := r.Get("testRegistryAlias"); a.Name() != "MD4" {
+		t.Fatalf("Expected alias to resolve to MD4")
+	}
+	if err := r.RegisterAlias("testRegistryAlias2", "testRegistryAlias"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach for an alias of an alias, got: %v", err)
+	}
+
+	// Unregistering only affects the scoped Registry
+	if err := r.Unregister("MD1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a, _ := r.Get("MD1"); a == Approach(fake) {
+		t.Fatalf("Expected the fallback Approach to be returned")
+	}
+	if err := r.Unregister("MD2"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+
+	// Without a fallback, only scoped registrations are available
+	isolated := NewRegistry(nil)
+	if _, err := isolated.Get("MD1"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+	if names := isolated.List(); len(names) != 0 {
+		t.Fatalf("Expected no names, got: %v", names)
+	}
+}
+
+func TestFromBytesAuto(t *testing.T) {
+
+	data := []string{"Hello", "World!"}
+
+	b, name, err := ToBytes(data, WithSerialisationApproachName(MinDataAlias))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "MD5" {
+		t.Fatalf("Expected the alias to resolve to MD5, got: %s", name)
+	}
+
+	v, err := FromBytesAuto(b, name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCompareSliceValue[string](v, data, "[]string", t)
+
+	// Scoped registries are used in place of the global Registry
+	r := NewRegistry(nil)
+	r.Register(testNamedApproach{Approach: NewMinDataApproachWithVersion(V3), name: "testAuto"})
+
+	if _, _, err := ToBytes(data, WithSerialisationApproachName("testAuto")); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+	if _, err := FromBytesAuto(b, name, WithRegistry(r)); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+
+	b, name, err = ToBytes(data, WithRegistry(r), WithSerialisationApproachName("testAuto"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err = FromBytesAuto(b, name, WithRegistry(r))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCompareSliceValue[string](v, data, "[]string", t)
+
+	// The last approach option applies
+	_, name, err = ToBytes(data, WithSerialisationApproachName("MD2"), WithSerialisationApproach(NewMinDataApproachWithVersion(V4)))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "MD4" {
+		t.Fatalf("Expected MD4, got: %s", name)
+	}
+
+	items := []any{int64(1), "two"}
+	b, name, err = ToBytesMany(items, WithSerialisationApproachName("MD3"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vs, err := FromBytesManyAuto(b, name)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(vs) != len(items) || vs[0] != items[0] || vs[1] != items[1] {
+		t.Fatalf("Data mismatch: expected %v, got: %v", items, vs)
+	}
+	if _, err := FromBytesManyAuto(b, "testUnknown"); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+}
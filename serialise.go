@@ -1,10 +1,10 @@
 package serialise
 
 import (
-	"bytes"
 	"compress/flate"
 	"errors"
-	"io"
+	"slices"
+	"sync"
 )
 
 // TypeID identifies the types that are supported by serialisation
@@ -55,6 +55,26 @@ const (
 	ByteSliceType
 	ByteSliceSliceType
 	NilType
+	TypedNilType // nil pointer of a specific type, e.g. (*int8)(nil)
+	BinaryMarshalerType
+	TextMarshalerType
+	SliceType
+	MapType
+	NamedType
+	StructType
+	PointerType
+	InterfaceType
+	RefType
+	BackRefType
+	BitSetType
+)
+
+// MinUserTypeID and MaxUserTypeID bound the range of TypeID values that are reserved
+// for user-defined types registered using RegisterType.  TypeID values below this range
+// are reserved for future use by this package.
+const (
+	MinUserTypeID TypeID = 64
+	MaxUserTypeID TypeID = 127
 )
 
 // Approach implements the mechanism to be used for serialisation
@@ -70,10 +90,55 @@ type Approach interface {
 	IsSerialisable(v any) bool
 }
 
+// DecodeOptions are the Options that adjust how an Approach deserialises data
+type DecodeOptions struct {
+	// ZeroCopy requests that []byte and string values alias the data being deserialised
+	ZeroCopy bool
+	// BoolsAsBitSet requests that a []bool is deserialised as a *BitSet
+	BoolsAsBitSet bool
+	// Interner, if set, provides deserialised string values so that repeated strings share memory
+	Interner Interner
+}
+
+// OptionsUnpacker is an optional extension of Approach, implemented by Approaches
+// whose deserialisation can be adjusted using DecodeOptions
+type OptionsUnpacker interface {
+	// UnpackWithOptions deserialises an instance from the byte slice, as adjusted by opts
+	UnpackWithOptions(data []byte, opts DecodeOptions) (any, error)
+}
+
+// AppendPacker is an optional extension of Approach, implemented by Approaches
+// that can serialise directly into a caller-provided buffer
+type AppendPacker interface {
+	// AppendPack appends the serialised instance to dst, returning the extended slice
+	AppendPack(dst []byte, data any) ([]byte, error)
+}
+
+// Sizer is an optional extension of Approach, implemented by Approaches that can
+// determine the length of the serialised data without returning the serialised data
+type Sizer interface {
+	// SizeOf returns the exact length of the output of Pack for the instance
+	SizeOf(v any) (int, error)
+}
+
+// quickSizer is an optional extension of Sizer, implemented by Approaches that can only
+// determine the length of some instances without serialising them
+type quickSizer interface {
+	// quickSizeOf returns the exact length of the output of Pack for the instance, or
+	// false if the length can only be determined by serialising the instance
+	quickSizeOf(v any) (int, bool, error)
+}
+
 // Options adjust how serialisation is performed
 type Options struct {
 	// Approach specifies which serialisation method is to be used
 	Approach Approach
+	// ApproachName specifies the name of the serialisation method to be used, which is
+	// retrieved from the Registry if Approach is not specified
+	ApproachName string
+	// Registry is used to retrieve Approaches by name.
+	// If nil (or unset), then the global Registry is used.
+	Registry *Registry
 	// Encryptor will encrypt the provided data
 	Encryptor func(data []byte) ([]byte, error)
 	// Decryptor will decrypt the provided data
@@ -81,12 +146,61 @@ type Options struct {
 	// FlateThreshold determines the point at which Flate compression will be applied
 	// Setting to -1 indicates no compression to be used, whatever size
 	FlateThreshold int
+	// CompressionPolicy determines whether Flate compression will be applied.
+	// If nil (or unset), then a threshold policy using FlateThreshold is applied.
+	CompressionPolicy CompressionPolicy
+	// CompressionLevel is the Flate compression level, from flate.HuffmanOnly to flate.BestCompression.
+	// If 0 (or unset), then defaultCompressionLevel (flate.BestCompression) is used.
+	CompressionLevel int
+	// Dictionaries are the preset dictionaries available to Flate.  The first is used when
+	// compressing, and the dictionary identified within the data is used when decompressing.
+	Dictionaries []*CompressionDictionary
+	// ZeroCopy determines whether deserialised []byte and string values alias the data
+	ZeroCopy bool
+	// BoolsAsBitSet determines whether a deserialised []bool is returned as a *BitSet
+	BoolsAsBitSet bool
+	// Interner provides deserialised string values, so that repeated strings share memory
+	Interner Interner
+	// Concurrency is the maximum number of goroutines used by ToBytesMany and FromBytesMany
+	// to serialise and deserialise items.  Values of 0 or 1 process items sequentially.
+	Concurrency int
+	// MaxSize is the maximum length of serialised data, before compression and encryption.
+	// If 0 (or unset), then the length is not limited.
+	MaxSize int
+}
+
+// decodeOptions returns the subset of the Options that are passed to an OptionsUnpacker
+func (o *Options) decodeOptions() DecodeOptions {
+	return DecodeOptions{
+		ZeroCopy:      o.ZeroCopy,
+		BoolsAsBitSet: o.BoolsAsBitSet,
+		Interner:      o.Interner,
+	}
 }
 
 // WithSerialisationApproach sets the serialisation approach to be used when calling ToBytes()
 func WithSerialisationApproach(approach Approach) func(*Options) {
 	return func(so *Options) {
 		so.Approach = approach
+		so.ApproachName = ""
+	}
+}
+
+// WithSerialisationApproachName sets the serialisation approach to be used when calling ToBytes()
+// to the Approach registered with the name or alias, in the Registry provided using WithRegistry
+// or otherwise the global Registry.  ErrUnknownApproach is returned if the name is not registered.
+func WithSerialisationApproachName(name string) func(*Options) {
+	return func(so *Options) {
+		so.Approach = nil
+		so.ApproachName = name
+	}
+}
+
+// WithRegistry sets the Registry used to retrieve Approaches by name, by FromBytesAuto,
+// FromBytesManyAuto and WithSerialisationApproachName, in place of the global Registry.
+func WithRegistry(r *Registry) func(*Options) {
+	return func(so *Options) {
+		so.Registry = r
 	}
 }
 
@@ -95,12 +209,113 @@ func WithSerialisationApproach(approach Approach) func(*Options) {
 // If value is 0 (or unset), then defaultFlateThreshold (25) is used.
 // For other values, Flate will be invoked when serialised data is beyond the threshold value.
 // This allows users to balance between runtime performance and size of serialised data.
+// WithFlateThreshold replaces any CompressionPolicy provided by an earlier option.
 func WithFlateThreshold(thresholdInBytes int) func(*Options) {
 	return func(so *Options) {
 		so.FlateThreshold = thresholdInBytes
+		so.CompressionPolicy = nil
+	}
+}
+
+// WithCompressionPolicy sets the policy that determines whether Flate compression is applied,
+// such as NewEntropyPolicy, which avoids the cpu cost of compressing data that is already compressed.
+// If policy is nil, then the threshold set by WithFlateThreshold is applied.
+func WithCompressionPolicy(policy CompressionPolicy) func(*Options) {
+	return func(so *Options) {
+		so.CompressionPolicy = policy
+	}
+}
+
+// WithCompressionLevel sets the level of compression used by Flate, allowing users to trade
+// the size of serialised data against the cpu cost of compressing it.  Valid values are
+// flate.HuffmanOnly, flate.DefaultCompression and flate.BestSpeed to flate.BestCompression.
+// If value is 0 (or unset), then defaultCompressionLevel (flate.BestCompression) is used;
+// use WithFlateThreshold(-1) to disable compression.
+func WithCompressionLevel(level int) func(*Options) {
+	return func(so *Options) {
+		so.CompressionLevel = level
+	}
+}
+
+// WithCompressionDictionary provides a preset dictionary to Flate, which improves the compression
+// of small payloads that share content with the dictionary.  The first dictionary provided is used
+// by ToBytes, AppendBytes and ToBytesMany, which record its ID in their output.  The option can be
+// provided more than once to FromBytes and FromBytesMany, which select the dictionary identified
+// by the data, returning ErrUnknownCompressionDictionary if it has not been provided.
+func WithCompressionDictionary(dict *CompressionDictionary) func(*Options) {
+	return func(so *Options) {
+		if dict != nil {
+			so.Dictionaries = append(so.Dictionaries, dict)
+		}
+	}
+}
+
+// WithZeroCopy requests that FromBytes and FromBytesMany return []byte and string values
+// that alias the data being deserialised, avoiding the cost of copying.  If the data was
+// compressed or encrypted then the values alias the decompressed or decrypted buffer
+// rather than the caller's data.  Aliased data must not be modified while the
+// deserialised values are in use.
+// Only applies to Approaches that implement OptionsUnpacker.
+func WithZeroCopy() func(*Options) {
+	return func(so *Options) {
+		so.ZeroCopy = true
 	}
 }
 
+// WithCopy requests that FromBytes and FromBytesMany return values that are fully
+// independent of the data being deserialised.  This is the default behaviour.
+// Only applies to Approaches that implement OptionsUnpacker.
+func WithCopy() func(*Options) {
+	return func(so *Options) {
+		so.ZeroCopy = false
+	}
+}
+
+// WithBoolsAsBitSet requests that FromBytes returns a []bool as a *BitSet, avoiding
+// the memory cost of a bool per byte for large slices.  Data serialised by MinData V4
+// onwards is read directly from its bit-packed form.  This applies to a []bool that is
+// the deserialised value, rather than a []bool held within a slice, map or struct.
+// Only applies to Approaches that implement OptionsUnpacker.
+func WithBoolsAsBitSet() func(*Options) {
+	return func(so *Options) {
+		so.BoolsAsBitSet = true
+	}
+}
+
+// WithStringInterning requests that FromBytes and FromBytesMany obtain deserialised string
+// values from the Interner, so that repeated strings share memory across calls.
+// Interned strings do not alias the data being deserialised, even if WithZeroCopy is used.
+// Only applies to Approaches that implement OptionsUnpacker.
+func WithStringInterning(interner Interner) func(*Options) {
+	return func(so *Options) {
+		so.Interner = interner
+	}
+}
+
+// WithConcurrency allows ToBytesMany and FromBytesMany to serialise and deserialise items
+// using a pool of up to n goroutines, which can reduce the elapsed time for large batches.
+// The output and the order of items are unaffected, and if any items fail then the error
+// for the first failing item is returned, as for sequential processing.
+// The Approach must be safe for concurrent use.
+func WithConcurrency(n int) func(*Options) {
+	return func(so *Options) {
+		so.Concurrency = n
+	}
+}
+
+// WithMaxSize limits the length of serialised data, before compression and encryption, to
+// maxBytes.  ToBytes, AppendBytes and ToBytesMany return ErrMaxSizeExceeded if the serialised
+// data is longer, and FromBytes and FromBytesMany return ErrMaxSizeExceeded rather than
+// decompress data beyond the limit, protecting services from excessive memory use.
+func WithMaxSize(maxBytes int) func(*Options) {
+	return func(so *Options) {
+		so.MaxSize = maxBytes
+	}
+}
+
+// ErrMaxSizeExceeded raised if serialised data is longer than the limit set using WithMaxSize
+var ErrMaxSizeExceeded = errors.New("serialised data exceeds the maximum size")
+
 // ErrUnexpectedSerialisationError raised if an invalid serialisation approach is specified
 var ErrUnexpectedSerialisationError = errors.New("unexpected error during serialisation")
 
@@ -110,21 +325,54 @@ var defaultSerialisationApproach = NewMinDataApproach()
 // FlateThreshold default of 25 bytes creates a good balance between cpu cost and space cost
 var defaultFlateThreshold int = 25
 
+// CompressionLevel default retains the historic behaviour of favouring space over cpu cost
+var defaultCompressionLevel int = flate.BestCompression
+
 // Default returns the current serialisation approach that will be used
 // by Pack, Unpack etc., if not set explicitly using the WithSerialisationApproach() option.
+// This reflects any Approach set using SetDefaults.
 func Default() Approach {
-	return defaultSerialisationApproach
+	a, err := resolveApproach(nil)
+	if err != nil {
+		return defaultSerialisationApproach
+	}
+	return a
 }
 
-// ToBytes returns a byte slice of the provded data.
-func ToBytes(data any, opts ...func(*Options)) ([]byte, string, error) {
+// optionsPool avoids allocating an Options on each call, as the Options
+// escape to the heap when passed to the option functions
+var optionsPool = sync.Pool{
+	New: func() any {
+		return new(Options)
+	},
+}
 
-	o := Options{}
+// newOptions returns the Options resulting from applying opts
+func newOptions(opts []func(*Options)) Options {
+	po := optionsPool.Get().(*Options)
+	*po = Options{}
+	for _, opt := range *defaultOptions.Load() {
+		opt(po)
+	}
 	for _, opt := range opts {
-		opt(&o)
+		opt(po)
 	}
+	o := *po
+	*po = Options{}
+	optionsPool.Put(po)
+	return o
+}
 
+// applyDefaults sets the default values of any Options required for serialisation that are unset
+func (o *Options) applyDefaults() error {
 	// Defaults to the current defaultSerialisationApproach value if not specified via opts
+	if o.Approach == nil && len(o.ApproachName) > 0 {
+		a, err := o.registry().Get(o.ApproachName)
+		if err != nil {
+			return err
+		}
+		o.Approach = a
+	}
 	if o.Approach == nil {
 		o.Approach = defaultSerialisationApproach
 	}
@@ -137,25 +385,81 @@ func ToBytes(data any, opts ...func(*Options)) ([]byte, string, error) {
 		o.FlateThreshold = -1 // Only valid value for negative input
 	}
 
-	b, err := o.Approach.Pack(data)
-	if err != nil {
-		return nil, "", err
+	// Defaults to the current defaultCompressionLevel value if not specified via opts
+	if o.CompressionLevel == 0 {
+		o.CompressionLevel = defaultCompressionLevel
 	}
+	return nil
+}
 
-	b, err = deflate(b, o.FlateThreshold)
+// exceedsMaxSize reports whether serialised data of length n exceeds the MaxSize
+func (o *Options) exceedsMaxSize(n int) bool {
+	return o.MaxSize > 0 && n > o.MaxSize
+}
+
+// registry returns the Registry used to retrieve Approaches by name
+func (o *Options) registry() *Registry {
+	if o.Registry != nil {
+		return o.Registry
+	}
+	return registry
+}
+
+// ToBytes returns a byte slice of the provded data.
+func ToBytes(data any, opts ...func(*Options)) ([]byte, string, error) {
+	return AppendBytes(nil, data, opts...)
+}
+
+// AppendBytes appends the serialised data to dst, returning the extended slice and the
+// name of the Approach used.  The appended bytes are identical to the output of ToBytes,
+// so that buffers can be reused across calls, for example by using a sync.Pool.
+// If the Approach implements AppendPacker then the data is serialised directly into dst,
+// so that no allocations are required for scalar values once dst has sufficient capacity,
+// provided that compression and encryption are not applied.
+// If an error is returned, the returned slice holds the original contents of dst, so that
+// the buffer can still be reused.
+func AppendBytes(dst []byte, data any, opts ...func(*Options)) ([]byte, string, error) {
+
+	n := len(dst)
+
+	o := newOptions(opts)
+	if err := o.applyDefaults(); err != nil {
+		return dst, "", err
+	}
+
+	dst = append(dst, 0) // Reserved for the compression flag
+
+	var b []byte
+	var err error
+	if ap, ok := o.Approach.(AppendPacker); ok {
+		b, err = ap.AppendPack(dst, data)
+	} else {
+		b, err = o.Approach.Pack(data)
+		b = append(dst, b...)
+	}
 	if err != nil {
-		return nil, "", err
+		return dst[:n], "", err
+	}
+	dst = b
+	if o.exceedsMaxSize(len(dst) - n - 1) {
+		return dst[:n], "", ErrMaxSizeExceeded
 	}
 
+	if b, err = deflate(dst, n, &o); err != nil {
+		return dst[:n], "", err
+	}
+	dst = b
+
 	// Apply optional encryption
 	if o.Encryptor != nil {
-		b, err = o.Encryptor(b)
+		b, err := o.Encryptor(dst[n:])
 		if err != nil {
-			return nil, "", err
+			return dst[:n], "", err
 		}
+		dst = append(dst[:n], b...)
 	}
 
-	return b, o.Approach.Name(), nil
+	return dst, o.Approach.Name(), nil
 }
 
 // ErrNoDataToDeserialise raised if nil or empty byte slice is used in FromBytes
@@ -185,10 +489,7 @@ func FromBytes(data []byte, approach Approach, opts ...func(*Options)) (v any, e
 		return nil, ErrInvalidSerialisationApproach
 	}
 
-	o := Options{}
-	for _, opt := range opts {
-		opt(&o)
-	}
+	o := newOptions(opts)
 
 	// Apply optional encryption
 	var b []byte = data
@@ -200,12 +501,36 @@ func FromBytes(data []byte, approach Approach, opts ...func(*Options)) (v any, e
 		}
 	}
 
-	b, err = reflate(b)
+	b, err = reflate(b, &o)
 	if err != nil {
 		return nil, err
 	}
+	if o.exceedsMaxSize(len(b)) {
+		return nil, ErrMaxSizeExceeded
+	}
 
-	return approach.Unpack(b)
+	return unpack(approach, b, &o)
+}
+
+// FromBytesAuto deserialises the byte slice to an instance using the Approach registered with
+// the name returned by ToBytes, retrieved from the Registry provided using WithRegistry or
+// otherwise the global Registry.  ErrUnknownApproach is returned if the name is not registered.
+func FromBytesAuto(data []byte, name string, opts ...func(*Options)) (any, error) {
+	o := newOptions(opts)
+
+	approach, err := o.registry().Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(data, approach, opts...)
+}
+
+// unpack deserialises the data using the approach, passing the decode options if supported
+func unpack(approach Approach, data []byte, o *Options) (any, error) {
+	if ou, ok := approach.(OptionsUnpacker); ok {
+		return ou.UnpackWithOptions(data, o.decodeOptions())
+	}
+	return approach.Unpack(data)
 }
 
 // ToBytesMany returns a byte slice of the provded data, individually packing all the items
@@ -214,25 +539,12 @@ func FromBytes(data []byte, approach Approach, opts ...func(*Options)) (v any, e
 // by the selected Approach.
 func ToBytesMany(data []any, opts ...func(*Options)) ([]byte, string, error) {
 
-	o := Options{}
-	for _, opt := range opts {
-		opt(&o)
-	}
-
-	// Defaults to the current defaultSerialisationApproach value if not specified via opts
-	if o.Approach == nil {
-		o.Approach = defaultSerialisationApproach
-	}
-
-	// Defaults to the current defaultFlateThreshold value if not specified via opts
-	if o.FlateThreshold == 0 {
-		o.FlateThreshold = defaultFlateThreshold
-	}
-	if o.FlateThreshold < 0 {
-		o.FlateThreshold = -1 // Only valid value for negative input
+	o := newOptions(opts)
+	if err := o.applyDefaults(); err != nil {
+		return nil, "", err
 	}
 
-	output := make([]byte, 0, 128)
+	output := make([]byte, 1, 128) // Reserves the first byte for the compression flag
 
 	b, err := ToBytesI64(int64(len(data)))
 	if err != nil {
@@ -241,23 +553,26 @@ func ToBytesMany(data []any, opts ...func(*Options)) ([]byte, string, error) {
 
 	output = append(output, b...)
 
-	for _, item := range data {
-
-		b, err := o.Approach.Pack(item)
-		if err != nil {
-			return nil, "", err
+	if sz, ok := o.Approach.(Sizer); ok {
+		sizeOf := func(v any) (int, bool, error) {
+			size, err := sz.SizeOf(v)
+			return size, true, err
 		}
-
-		bl, err := ToBytesI64(int64(len(b)))
-		if err != nil {
-			return nil, "", err
+		if qs, ok := o.Approach.(quickSizer); ok {
+			sizeOf = qs.quickSizeOf
 		}
-
-		output = append(output, bl...)
-		output = append(output, b...)
+		output, err = appendItemsSized(output, data, sizeOf, &o)
+	} else {
+		output, err = appendItems(output, data, &o)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if o.exceedsMaxSize(len(output) - 1) {
+		return nil, "", ErrMaxSizeExceeded
 	}
 
-	output, err = deflate(output, o.FlateThreshold)
+	output, err = deflate(output, 0, &o)
 	if err != nil {
 		return nil, "", err
 	}
@@ -273,34 +588,141 @@ func ToBytesMany(data []any, opts ...func(*Options)) ([]byte, string, error) {
 	return output, o.Approach.Name(), nil
 }
 
-func deflate(b []byte, threshold int) ([]byte, error) {
-	var flag byte = 0
-	if threshold > -1 && len(b) > threshold { // Trading of time cost of Flate against space... for small []byte cost is too high
-		oLen := len(b)
-		var buf bytes.Buffer
-		writer, _ := flate.NewWriter(&buf, flate.BestCompression)
-		_, err := writer.Write(b)
+// appendItems appends each item of data, prefixed by its length, once all items have been serialised
+func appendItems(output []byte, data []any, o *Options) ([]byte, error) {
+	items := make([][]byte, len(data))
+	err := forEachItem(len(data), o.Concurrency, func(i int) (e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = ErrUnexpectedSerialisationError
+			}
+		}()
+
+		items[i], e = o.Approach.Pack(data[i])
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+
+		bl, err := ToBytesI64(int64(len(item)))
+		if err != nil {
+			return nil, err
+		}
+
+		output = append(output, bl...)
+		output = append(output, item...)
+	}
+	return output, nil
+}
+
+// appendItemsSized appends each item of data, prefixed by its length, using the sizes
+// of the items to allocate the output once, into which the items are then serialised.
+// Items whose length can only be determined by serialising them are serialised first
+// and copied into the output, so that no item is serialised twice.
+func appendItemsSized(output []byte, data []any, sizeOf func(any) (int, bool, error), o *Options) ([]byte, error) {
+	sizes := make([]int, len(data))
+	packed := make([][]byte, len(data))
+	err := forEachItem(len(data), o.Concurrency, func(i int) (e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = ErrUnexpectedSerialisationError
+			}
+		}()
+
+		var ok bool
+		if sizes[i], ok, e = sizeOf(data[i]); e != nil || ok {
+			return e
+		}
+		if packed[i], e = o.Approach.Pack(data[i]); e == nil && packed[i] == nil {
+			packed[i] = []byte{} // Distinguishes an empty item from an item that is serialised in place
+		}
+		sizes[i] = len(packed[i])
+		return e
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(output) + len(data)*int(SizeOfI64())
+	for _, size := range sizes {
+		total += size
+	}
+	output = slices.Grow(output, total-len(output))
+
+	offsets := make([]int, len(data))
+	for i, size := range sizes {
+		bl, err := ToBytesI64(int64(size))
 		if err != nil {
 			return nil, err
 		}
-		writer.Close()
-		bf := buf.Bytes()
 
-		if oLen > len(bf) { // Sometimes Flate creates a bigger output than its input
-			flag = 1
-			b = bf
+		output = append(output, bl...)
+		offsets[i] = len(output)
+		output = output[:len(output)+size]
+	}
+
+	ap, isAppendPacker := o.Approach.(AppendPacker)
+
+	err = forEachItem(len(data), o.Concurrency, func(i int) (e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = ErrUnexpectedSerialisationError
+			}
+		}()
+
+		if packed[i] != nil {
+			copy(output[offsets[i]:], packed[i])
+			return nil
+		}
+
+		// Each item is written to its own region of output, so items can be serialised concurrently
+		dst := output[offsets[i] : offsets[i] : offsets[i]+sizes[i]]
+
+		var item []byte
+		if isAppendPacker {
+			item, e = ap.AppendPack(dst, data[i])
+		} else {
+			item, e = o.Approach.Pack(data[i])
+			if e == nil && len(item) == sizes[i] {
+				item = append(dst, item...)
+			}
+		}
+		if e != nil {
+			return e
 		}
+		if len(item) != sizes[i] || (len(item) > 0 && &item[0] != &output[offsets[i]]) {
+			return ErrUnexpectedSerialisationError // The item was not serialised in place with the reported size
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return append([]byte{flag}, b...), nil
+	return output, nil
 }
 
-func reflate(b []byte) ([]byte, error) {
-	if b[0] == 1 {
-		r := flate.NewReader(bytes.NewReader(b[1:]))
-		return io.ReadAll(r)
-	} else {
-		return b[1:], nil
+// SizeOf returns the length of the data when serialised by the Approach, before any
+// compression or encryption is applied, allowing buffers to be sized and size limits to be
+// checked before serialising.  If the Approach does not implement Sizer, then the data
+// is serialised to determine its length.
+func SizeOf(data any, opts ...func(*Options)) (int, error) {
+	o := newOptions(opts)
+	if err := o.applyDefaults(); err != nil {
+		return 0, err
+	}
+
+	if sz, ok := o.Approach.(Sizer); ok {
+		return sz.SizeOf(data)
 	}
+
+	b, err := o.Approach.Pack(data)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
 }
 
 // ErrFromBytesManyInvalidData raised if FromBytesMany is provided with invalid byte slice
@@ -324,10 +746,7 @@ func FromBytesMany(data []byte, approach Approach, opts ...func(*Options)) (v []
 		return nil, ErrInvalidSerialisationApproach
 	}
 
-	o := Options{}
-	for _, opt := range opts {
-		opt(&o)
-	}
+	o := newOptions(opts)
 
 	// Apply optional encryption
 	var b []byte = data
@@ -339,10 +758,13 @@ func FromBytesMany(data []byte, approach Approach, opts ...func(*Options)) (v []
 		}
 	}
 
-	b, err = reflate(b)
+	b, err = reflate(b, &o)
 	if err != nil {
 		return nil, err
 	}
+	if o.exceedsMaxSize(len(b)) {
+		return nil, ErrMaxSizeExceeded
+	}
 
 	var sizeI64 = SizeOfI64()
 
@@ -352,23 +774,61 @@ func FromBytesMany(data []byte, approach Approach, opts ...func(*Options)) (v []
 	}
 	b = b[sizeI64:]
 
-	output := make([]any, size)
+	items := make([][]byte, size)
 
 	for offset := range size {
 		itemSize, err := FromBytesI64(b[0:sizeI64])
 		if err != nil {
 			return nil, err
 		}
-		itemData := b[sizeI64 : sizeI64+itemSize]
+		items[offset] = b[sizeI64 : sizeI64+itemSize]
+		b = b[sizeI64+itemSize:]
+	}
 
-		v, err := approach.Unpack(itemData)
-		if err != nil {
-			return nil, err
-		}
+	output := make([]any, size)
 
-		output[offset] = v
-		b = b[sizeI64+itemSize:]
+	err = forEachItem(len(items), o.Concurrency, func(i int) (e error) {
+		defer func() {
+			if r := recover(); r != nil {
+				e = ErrFromBytesManyInvalidData
+			}
+		}()
+
+		output[i], e = unpack(approach, items[i], &o)
+		return e
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return output, nil
 }
+
+// FromBytesManyAuto deserialises the byte slice to an array of instances using the Approach registered
+// with the name returned by ToBytesMany, retrieved from the Registry provided using WithRegistry or
+// otherwise the global Registry.  ErrUnknownApproach is returned if the name is not registered.
+func FromBytesManyAuto(data []byte, name string, opts ...func(*Options)) ([]any, error) {
+	o := newOptions(opts)
+
+	approach, err := o.registry().Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytesMany(data, approach, opts...)
+}
+
+// Transcode deserialises data using the from Approach and serialises the result using the
+// to Approach, so that stored data can be upgraded to a later version of an Approach, such as
+// from NewMinDataApproachWithVersion(V1) to the latest version of MinData.  The options,
+// such as encryption and compression, apply to both deserialisation and serialisation.
+func Transcode(data []byte, from, to Approach, opts ...func(*Options)) ([]byte, string, error) {
+	if to == nil {
+		return nil, "", ErrInvalidSerialisationApproach
+	}
+
+	v, err := FromBytes(data, from, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return ToBytes(v, append(slices.Clip(opts), WithSerialisationApproach(to))...)
+}
@@ -2,7 +2,9 @@ package serialise
 
 import (
 	"bytes"
+	"compress/flate"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,6 +71,9 @@ func testComparePtrValue[T comparable](a, b any, name string, t *testing.T) {
 			if (aa == nil && v != nil) || (aa != nil && v == nil) {
 				t.Fatalf("Pointer mismatch: expected %v, got: %v", v, aa)
 			}
+			if aa == nil && v == nil {
+				return
+			}
 			if *aa != *v {
 				t.Fatalf("Data mismatch: expected %v, got: %v", *v, *aa)
 			}
@@ -329,50 +334,202 @@ func TestToMinBytes(t *testing.T) {
 	}
 }
 
-func runSliceTest[T comparable](st TypeID, data []T, eleSize int64, t *testing.T) {
+func TestToBytesNilPointers(t *testing.T) {
 
-	b, err := packSimpleSliceMD(st, data)
-	if err != nil {
-		t.Fatalf("Unexpected error when packing slice: %v", err)
+	type testData struct {
+		V        any
+		TypeName string
 	}
 
-	v, err := unpackSimpleSliceMD[T](b[1:], eleSize)
-	if err != nil {
-		t.Fatalf("Unexpected error when unpacking slice: %v", err)
+	tests := []testData{
+		{(*int8)(nil), "*int8"},
+		{(*int16)(nil), "*int16"},
+		{(*int32)(nil), "*int32"},
+		{(*int64)(nil), "*int64"},
+		{(*uint8)(nil), "*uint8"},
+		{(*uint16)(nil), "*uint16"},
+		{(*uint32)(nil), "*uint32"},
+		{(*uint64)(nil), "*uint64"},
+		{(*float32)(nil), "*float32"},
+		{(*float64)(nil), "*float64"},
+		{(*bool)(nil), "*bool"},
+		{(*time.Duration)(nil), "*time.Duration"},
+		{(*string)(nil), "*string"},
+		{(*time.Time)(nil), "*time.Time"},
 	}
 
-	vv, ok := v.([]T)
-	if !ok {
-		t.Fatalf("Unexpected type when unpacking slice: %T", v)
+	approach := NewMinDataApproach()
+
+	for _, test := range tests {
+
+		if !approach.IsSerialisable(test.V) {
+			t.Fatalf("Expected %s to be serialisable", test.TypeName)
+		}
+
+		b, _, err := ToBytes(test.V, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.TypeName, err)
+		}
+
+		v, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error for %s: %v", test.TypeName, err)
+		}
+
+		if v == nil {
+			t.Fatalf("Expected typed nil for %s, got untyped nil", test.TypeName)
+		}
+		if fmt.Sprintf("%T", v) != test.TypeName {
+			t.Fatalf("Type mismatch: expected: %s, got: %T", test.TypeName, v)
+		}
+		if fmt.Sprintf("%v", v) != "<nil>" {
+			t.Fatalf("Expected nil pointer for %s, got: %v", test.TypeName, v)
+		}
 	}
 
-	if len(data) != len(vv) {
-		t.Fatalf("Unexpected size difference: expected: %d, got: %d", len(data), len(vv))
+	// An empty string pointer must remain distinct from a nil *string
+	var s string
+	b, _, _ := ToBytes(&s, WithSerialisationApproach(approach))
+	v, err := FromBytes(b, approach)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
+	compareValue(v, &s, "*string", t)
+}
+
+func runSliceTest[T comparable](st TypeID, data []T, t *testing.T) {
+
+	prefix := []byte("prefix")
+	for _, version := range []MinDataVersion{V1, V2, V3, V4, V5} {
+		approach := NewMinDataApproachWithVersion(version)
 
-	for i, vvv := range vv {
-		if data[i] != vvv {
-			t.Fatalf("Unexpected value difference at %d: expected: %v, got: %v", i, data[i], vvv)
+		b, err := approach.(AppendPacker).AppendPack([]byte("prefix"), data)
+		if err != nil {
+			t.Fatalf("Unexpected error when packing slice: %v", err)
+		}
+		if !bytes.HasPrefix(b, prefix) {
+			t.Fatalf("Expected prefix to be retained, got: %x", b)
+		}
+		b = b[len(prefix):]
+		if version == V1 && TypeID(b[0]) != st {
+			t.Fatalf("Unexpected TypeID: expected: %v, got: %v", st, b[0])
+		}
+
+		v, err := approach.Unpack(b)
+		if err != nil {
+			t.Fatalf("Unexpected error when unpacking slice: %v", err)
+		}
+
+		vv, ok := v.([]T)
+		if !ok {
+			t.Fatalf("Unexpected type when unpacking slice: %T", v)
 		}
-	}
 
+		if len(data) != len(vv) {
+			t.Fatalf("Unexpected size difference: expected: %d, got: %d", len(data), len(vv))
+		}
+
+		for i, vvv := range vv {
+			if data[i] != vvv {
+				t.Fatalf("Unexpected value difference at %d: expected: %v, got: %v", i, data[i], vvv)
+			}
+		}
+	}
 }
 
 func TestPackSlice(t *testing.T) {
 
-	runSliceTest(Int8SliceType, []int8{43, 21, 54}, 1, t)
-	runSliceTest(Int16SliceType, []int16{43, 21, 54}, 2, t)
-	runSliceTest(Int32SliceType, []int32{43, 21, 54}, 4, t)
-	runSliceTest(Int64SliceType, []int64{43, 21, 54}, 8, t)
-	runSliceTest(Uint16SliceType, []uint16{43, 21, 54}, 2, t)
-	runSliceTest(Uint32SliceType, []uint32{43, 21, 54}, 4, t)
-	runSliceTest(Uint64SliceType, []uint64{43, 21, 54}, 8, t)
-	runSliceTest(Float32SliceType, []float32{43, 21, 54}, 4, t)
-	runSliceTest(Float64SliceType, []float64{43, 21, 54}, 8, t)
-	runSliceTest(BoolSliceType, []bool{true, true, false}, 1, t)
+	runSliceTest(Int8SliceType, []int8{43, 21, 54}, t)
+	runSliceTest(Int16SliceType, []int16{43, 21, 54}, t)
+	runSliceTest(Int32SliceType, []int32{43, 21, 54}, t)
+	runSliceTest(Int64SliceType, []int64{43, 21, 54}, t)
+	runSliceTest(Uint16SliceType, []uint16{43, 21, 54}, t)
+	runSliceTest(Uint32SliceType, []uint32{43, 21, 54}, t)
+	runSliceTest(Uint64SliceType, []uint64{43, 21, 54}, t)
+	runSliceTest(Float32SliceType, []float32{43, 21, 54}, t)
+	runSliceTest(Float64SliceType, []float64{43, 21, 54}, t)
+	runSliceTest(BoolSliceType, []bool{true, true, false}, t)
 
 }
 
+func TestMinDataV1Format(t *testing.T) {
+
+	// MD1 output must remain stable, so that existing serialised data remains readable
+	tm := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		V   any
+		Hex string
+	}{
+		{nil, "2b"},
+		{int8(-5), "01fb"},
+		{ptrMD(int8(-5)), "02fb"},
+		{[]int8{1, -1}, "03020000000000000001ff"},
+		{int16(-300), "04d4fe"},
+		{ptrMD(int16(-300)), "05d4fe"},
+		{[]int16{1, -1}, "0602000000000000000100ffff"},
+		{int32(-70000), "0790eefeff"},
+		{ptrMD(int32(-70000)), "0890eefeff"},
+		{[]int32{1, -1}, "09020000000000000001000000ffffffff"},
+		{int64(-5000000000), "0a000efad5feffffff"},
+		{ptrMD(int64(-5000000000)), "0b000efad5feffffff"},
+		{[]int64{1, -1}, "0c02000000000000000100000000000000ffffffffffffffff"},
+		{uint8(200), "0dc8"},
+		{ptrMD(uint8(200)), "0ec8"},
+		{uint16(60000), "0f60ea"},
+		{ptrMD(uint16(60000)), "1060ea"},
+		{[]uint16{1, 65535}, "1102000000000000000100ffff"},
+		{uint32(4000000000), "1200286bee"},
+		{ptrMD(uint32(4000000000)), "1300286bee"},
+		{[]uint32{1, 2}, "1402000000000000000100000002000000"},
+		{uint64(1<<63 + 5), "150500000000000080"},
+		{ptrMD(uint64(1<<63 + 5)), "160500000000000080"},
+		{[]uint64{1, 2}, "17020000000000000001000000000000000200000000000000"},
+		{float32(-1.5), "180000c0bf"},
+		{ptrMD(float32(-1.5)), "190000c0bf"},
+		{[]float32{1.5, -2}, "1a02000000000000000000c03f000000c0"},
+		{float64(3.25), "1b0000000000000a40"},
+		{ptrMD(float64(3.25)), "1c0000000000000a40"},
+		{[]float64{1.5, -2}, "1d0200000000000000000000000000f83f00000000000000c0"},
+		{true, "1e01"},
+		{ptrMD(true), "1f01"},
+		{[]bool{true, false}, "2002000000000000000100"},
+		{time.Duration(-42), "21d6ffffffffffffff"},
+		{ptrMD(time.Duration(-42)), "22d6ffffffffffffff"},
+		{[]time.Duration{1, -1}, "2302000000000000000100000000000000ffffffffffffffff"},
+		{"Hi", "244869"},
+		{ptrMD("Hi"), "254869"},
+		{[]string{"a", "", "bc"}, "260300000000000000010000000000000061000000000000000002000000000000006263"},
+		{tm, "27010000000edd25742500000006ffff"},
+		{&tm, "28010000000edd25742500000006ffff"},
+		{[]byte("xy"), "297879"},
+		{[][]byte{[]byte("a"), {}}, "2a02000000000000000100000000000000610000000000000000"},
+		{[][]byte(nil), "2a"},
+		{[]string{}, "260000000000000000"},
+		{[]int64{}, "0c0000000000000000"},
+	}
+
+	approach := NewMinDataApproachWithVersion(V1)
+
+	for _, test := range tests {
+		b, err := approach.Pack(test.V)
+		if err != nil {
+			t.Fatalf("Unexpected error for %T: %v", test.V, err)
+		}
+		if fmt.Sprintf("%x", b) != test.Hex {
+			t.Fatalf("Format mismatch for %T (%v): expected: %s, got: %x", test.V, test.V, test.Hex, b)
+		}
+
+		v, err := approach.Unpack(b)
+		if err != nil {
+			t.Fatalf("Unexpected error for %T: %v", test.V, err)
+		}
+		if fmt.Sprintf("%T", v) != fmt.Sprintf("%T", test.V) {
+			t.Fatalf("Type mismatch: expected: %T, got: %T", test.V, v)
+		}
+	}
+}
+
 func TestToBytes(t *testing.T) {
 
 	type testData struct {
@@ -683,6 +840,155 @@ func TestToBytes_3(t *testing.T) {
 	}
 }
 
+func TestAppendBytes(t *testing.T) {
+
+	key := []byte("01234567890123456789012345678912")
+
+	tests := []any{
+		int16(42),
+		"Hello World",
+		[]string{"01234567890123456789012345678901234567890123456789"},
+		nil,
+	}
+
+	prefix := []byte("prefix")
+
+	for _, test := range tests {
+
+		b1, name1, err := ToBytes(test)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		b2, name2, err := AppendBytes(bytes.Clone(prefix), test)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if name1 != name2 {
+			t.Fatalf("Unexpected difference in names: got: %s rather than: %s", name2, name1)
+		}
+		if !bytes.Equal(b2[:len(prefix)], prefix) {
+			t.Fatalf("Unexpected change to prefix: %v", b2[:len(prefix)])
+		}
+		if !bytes.Equal(b1, b2[len(prefix):]) {
+			t.Fatalf("Unexpected variation in byte slices: %v vs %v", b1, b2[len(prefix):])
+		}
+
+		b3, _, err := AppendBytes(bytes.Clone(prefix), test, WithAESGCMEncryption(key))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		v, err := FromBytes(b3[len(prefix):], defaultSerialisationApproach, WithAESGCMEncryption(key))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		compareValue(v, test, fmt.Sprintf("%T", test), t)
+	}
+
+	// Scalars are serialised without allocation once the buffer has capacity
+	buf := make([]byte, 0, 64)
+	opt := WithSerialisationApproach(NewMinDataApproach())
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _, _ = AppendBytes(buf[:0], int16(42), opt)
+	})
+	if allocs != 0 {
+		t.Fatalf("Expected no allocations, got: %v", allocs)
+	}
+
+	// The original contents of the buffer are returned on error, so that it can be reused
+	for i, test := range []struct {
+		V    any
+		Opts []func(*Options)
+	}{
+		{int16(42), []func(*Options){WithSerialisationApproachName("testUnknown")}},
+		{make(chan int), nil},
+		{[]string{"01234567890123456789"}, []func(*Options){WithMaxSize(10)}},
+		{int16(42), []func(*Options){WithAESGCMEncryption([]byte("short"))}},
+	} {
+		b, name, err := AppendBytes(bytes.Clone(prefix), test.V, test.Opts...)
+		if err == nil {
+			t.Fatalf("(%d) Expected an error", i)
+		}
+		if name != "" || !bytes.Equal(b, prefix) {
+			t.Fatalf("(%d) Expected the original buffer, got: %q, %s", i, b, name)
+		}
+	}
+}
+
+func TestZeroCopy(t *testing.T) {
+
+	type testData struct {
+		V      any
+		First  func(v any) byte
+		Expect byte
+	}
+
+	tests := []testData{
+		{[]byte("Hello"), func(v any) byte { return v.([]byte)[0] }, 'H'},
+		{"Hello", func(v any) byte { return v.(string)[0] }, 'H'},
+		{[][]byte{[]byte("Hello")}, func(v any) byte { return v.([][]byte)[0][0] }, 'H'},
+		{[]string{"Hello"}, func(v any) byte { return v.([]string)[0][0] }, 'H'},
+		{map[string][]byte{"k": []byte("Hello")}, func(v any) byte { return v.(map[string][]byte)["k"][0] }, 'H'},
+	}
+
+	approach := NewMinDataApproach()
+
+	// Modifies all occurrences of 'H' within the serialised data
+	corrupt := func(b []byte) {
+		for i := range b {
+			if b[i] == 'H' {
+				b[i] = 'J'
+			}
+		}
+	}
+
+	for _, test := range tests {
+
+		b, _, err := ToBytes(test.V, WithSerialisationApproach(approach), WithFlateThreshold(-1))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		vCopy, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		vExplicitCopy, err := FromBytes(b, approach, WithZeroCopy(), WithCopy())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		vZero, err := FromBytes(b, approach, WithZeroCopy())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		corrupt(b)
+
+		if c := test.First(vCopy); c != test.Expect {
+			t.Fatalf("Expected copied %T to be independent of the data, got: %c", test.V, c)
+		}
+		if c := test.First(vExplicitCopy); c != test.Expect {
+			t.Fatalf("Expected copied %T to be independent of the data, got: %c", test.V, c)
+		}
+		if c := test.First(vZero); c != 'J' {
+			t.Fatalf("Expected zero copy %T to alias the data, got: %c", test.V, c)
+		}
+	}
+
+	// Appending to an aliased []byte must not overwrite the remaining data
+	b, _, _ := ToBytes([][]byte{[]byte("ab"), []byte("cd")}, WithFlateThreshold(-1))
+	v, err := FromBytes(b, approach, WithZeroCopy())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bss := v.([][]byte)
+	_ = append(bss[0], 'x')
+	if string(bss[1]) != "cd" {
+		t.Fatalf("Unexpected overwrite of data: %s", bss[1])
+	}
+}
+
 func TestToBytesMany(t *testing.T) {
 
 	tests := [][]any{
@@ -776,14 +1082,98 @@ func TestToBytesMany_1(t *testing.T) {
 	}
 }
 
-func benchToBytes(v any, b *testing.B) {
-	bv, name, err := ToBytes(v)
+func TestCompressionLevel(t *testing.T) {
+
+	data := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+
+	for _, level := range []int{0, flate.HuffmanOnly, flate.DefaultCompression, flate.BestSpeed, flate.BestCompression} {
+		for range 3 { // Exercise reuse of pooled writers and readers
+			b, _, err := ToBytes(data, WithCompressionLevel(level))
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", level, err)
+			}
+			if b[0] != 1 {
+				t.Fatalf("(%d) Expected data to be compressed", level)
+			}
+			if len(b) >= len(data) {
+				t.Fatalf("(%d) Expected compressed data to be smaller: got: %d", level, len(b))
+			}
+
+			v, err := FromBytes(b, defaultSerialisationApproach)
+			if err != nil {
+				t.Fatalf("(%d) Unexpected error: %v", level, err)
+			}
+			if v.(string) != data {
+				t.Fatalf("(%d) Data mismatch after round trip", level)
+			}
+		}
+	}
+
+	for _, level := range []int{-3, 10} {
+		_, _, err := ToBytes(data, WithCompressionLevel(level))
+		if err != ErrInvalidCompressionLevel {
+			t.Fatalf("(%d) Expected ErrInvalidCompressionLevel, got: %v", level, err)
+		}
+	}
+}
+
+func TestToBytesManyConcurrency(t *testing.T) {
+
+	data := make([]any, 1000)
+	for i := range data {
+		switch i % 4 {
+		case 0:
+			data[i] = int64(i)
+		case 1:
+			data[i] = fmt.Sprintf("Item %d", i)
+		case 2:
+			data[i] = []float64{float64(i), -float64(i)}
+		default:
+			data[i] = nil
+		}
+	}
+
+	expected, _, err := ToBytesMany(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, n := range []int{2, 8, 2000} {
+
+		b, _, err := ToBytesMany(data, WithConcurrency(n))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", n, err)
+		}
+		if !bytes.Equal(b, expected) {
+			t.Fatalf("(%d) Unexpected variation in byte slices", n)
+		}
+
+		v, err := FromBytesMany(b, defaultSerialisationApproach, WithConcurrency(n))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", n, err)
+		}
+		if len(v) != len(data) {
+			t.Fatalf("(%d) Unexpected error in output length: expected: %d, got: %d", n, len(data), len(v))
+		}
+		for i := range data {
+			compareValue(v[i], data[i], fmt.Sprintf("%T", data[i]), t)
+		}
+	}
+
+	data[500] = make(chan int)
+	if _, _, err := ToBytesMany(data, WithConcurrency(8)); err != ErrMinDataTypeNotSerialisable {
+		t.Fatalf("Expected ErrMinDataTypeNotSerialisable, got: %v", err)
+	}
+}
+
+func benchToBytes(v any, b *testing.B, opts ...func(*Options)) {
+	bv, name, err := ToBytes(v, opts...)
 	if err != nil {
 		b.Fatalf("Unexpected error: %v", err)
 	}
 
 	for i := 0; i < b.N; i++ {
-		bbb, nn, err := ToBytes(v)
+		bbb, nn, err := ToBytes(v, opts...)
 		if err != nil {
 			b.Fatalf("(%d) Unexpected error: %v", i, err)
 		}
@@ -796,14 +1186,14 @@ func benchToBytes(v any, b *testing.B) {
 	}
 }
 
-func benchToBytesMany(v []any, b *testing.B) {
-	bv, name, err := ToBytesMany(v)
+func benchToBytesMany(v []any, b *testing.B, opts ...func(*Options)) {
+	bv, name, err := ToBytesMany(v, opts...)
 	if err != nil {
 		b.Fatalf("Unexpected error: %v", err)
 	}
 
 	for i := 0; i < b.N; i++ {
-		bbb, nn, err := ToBytesMany(v)
+		bbb, nn, err := ToBytesMany(v, opts...)
 		if err != nil {
 			b.Fatalf("(%d) Unexpected error: %v", i, err)
 		}
@@ -884,6 +1274,55 @@ func BenchmarkFromBytes_4(b *testing.B) {
 	benchToBytes([]float64{-1.234, 1.234}, b)
 }
 
+func BenchmarkToBytes_5(b *testing.B) {
+	benchPack(make([]float64, 1_000_000), b)
+}
+
+func BenchmarkFromBytes_5(b *testing.B) {
+	benchUnpack(make([]float64, 1_000_000), b)
+}
+
+func benchPack(v any, b *testing.B) {
+	approach := NewMinDataApproach()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := approach.Pack(v)
+		if err != nil {
+			b.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+	}
+}
+
+func benchUnpack(v any, b *testing.B) {
+	approach := NewMinDataApproach()
+	bv, err := approach.Pack(v)
+	if err != nil {
+		b.Fatalf("Unexpected error: %v", err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := approach.Unpack(bv)
+		if err != nil {
+			b.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+	}
+}
+
+func BenchmarkAppendBytes(b *testing.B) {
+	buf := make([]byte, 0, 64)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		buf, _, err = AppendBytes(buf[:0], int16(42))
+		if err != nil {
+			b.Fatalf("(%d) Unexpected error: %v", i, err)
+		}
+	}
+}
+
 func BenchmarkToBytesMany(b *testing.B) {
 	benchToBytesMany([]any{int16(42)}, b)
 }
@@ -896,6 +1335,22 @@ func BenchmarkToBytesMany_2(b *testing.B) {
 	benchToBytesMany([]any{string("Hello World")}, b)
 }
 
+func benchManyItems() []any {
+	v := make([]any, 10000)
+	for i := range v {
+		v[i] = []float64{float64(i), float64(i) / 3, float64(i) / 7}
+	}
+	return v
+}
+
+func BenchmarkToBytesMany_3(b *testing.B) {
+	benchToBytesMany(benchManyItems(), b)
+}
+
+func BenchmarkToBytesMany_4(b *testing.B) {
+	benchToBytesMany(benchManyItems(), b, WithConcurrency(4))
+}
+
 func BenchmarkFromBytesMany(b *testing.B) {
 	benchFromBytesMany([]any{int16(42)}, b)
 }
@@ -927,3 +1382,19 @@ func BenchmarkFromBytesMany_6(b *testing.B) {
 func BenchmarkFromBytesMany_7(b *testing.B) {
 	benchFromBytesMany([]any{int16(42), int16(42), int16(42), int16(42), int16(42), int16(42)}, b, WithFlateThreshold(-1))
 }
+
+func BenchmarkFromBytesMany_8(b *testing.B) {
+	benchFromBytesMany(benchManyItems(), b)
+}
+
+func BenchmarkFromBytesMany_9(b *testing.B) {
+	benchFromBytesMany(benchManyItems(), b, WithConcurrency(4))
+}
+
+func BenchmarkToBytes_6(b *testing.B) {
+	benchToBytes(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), b)
+}
+
+func BenchmarkToBytes_7(b *testing.B) {
+	benchToBytes(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20), b, WithCompressionLevel(flate.BestSpeed))
+}
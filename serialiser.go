@@ -0,0 +1,124 @@
+package serialise
+
+import (
+	"compress/flate"
+	"slices"
+	"sync/atomic"
+)
+
+// defaultOptions holds the options set by SetDefaults, which are applied before the
+// options passed to each call
+var defaultOptions atomic.Pointer[[]func(*Options)]
+
+func init() {
+	defaultOptions.Store(&[]func(*Options){})
+}
+
+// SetDefaults sets the options that are applied to all calls to ToBytes, FromBytes etc.,
+// before the options passed to each call, so that options such as the Approach, compression
+// and encryption need not be repeated at every call site.  The defaults replace any set
+// previously, and can be cleared by calling SetDefaults with no options.
+// An error is returned, and the defaults are unchanged, if the options are not valid.
+// SetDefaults is safe for concurrent use, but is intended to be called during startup.
+func SetDefaults(opts ...func(*Options)) error {
+	o := Options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	opts = slices.Clone(opts)
+	defaultOptions.Store(&opts)
+	return nil
+}
+
+// validate checks that the Options can be used for serialisation
+func (o *Options) validate() error {
+	if err := o.applyDefaults(); err != nil {
+		return err
+	}
+	if o.CompressionLevel < flate.HuffmanOnly || o.CompressionLevel > flate.BestCompression {
+		return ErrInvalidCompressionLevel
+	}
+	return nil
+}
+
+// Serialiser bundles the options used to serialise and deserialise data, such as the Approach,
+// compression, encryption and limits, so that they are configured once rather than at each call site.
+// Options passed to its methods are applied after those of the Serialiser, which are applied after
+// any set using SetDefaults.  A Serialiser is safe for concurrent use.
+type Serialiser struct {
+	opts []func(*Options)
+}
+
+// NewSerialiser creates a Serialiser using the options, returning an error if they are not valid
+func NewSerialiser(opts ...func(*Options)) (*Serialiser, error) {
+	o := newOptions(opts)
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return &Serialiser{
+		opts: slices.Clone(opts),
+	}, nil
+}
+
+// with returns the options of the Serialiser followed by opts
+func (s *Serialiser) with(opts []func(*Options)) []func(*Options) {
+	if len(opts) == 0 {
+		return s.opts
+	}
+	return slices.Concat(s.opts, opts)
+}
+
+// resolveApproach returns the Approach used for serialisation with the options
+func resolveApproach(opts []func(*Options)) (Approach, error) {
+	o := newOptions(opts)
+	if err := o.applyDefaults(); err != nil {
+		return nil, err
+	}
+	return o.Approach, nil
+}
+
+// ToBytes returns a byte slice of the provided data, as for the package function ToBytes
+func (s *Serialiser) ToBytes(data any, opts ...func(*Options)) ([]byte, string, error) {
+	return ToBytes(data, s.with(opts)...)
+}
+
+// AppendBytes appends the serialised data to dst, as for the package function AppendBytes
+func (s *Serialiser) AppendBytes(dst []byte, data any, opts ...func(*Options)) ([]byte, string, error) {
+	return AppendBytes(dst, data, s.with(opts)...)
+}
+
+// FromBytes deserialises the byte slice to an instance using the Approach of the Serialiser
+func (s *Serialiser) FromBytes(data []byte, opts ...func(*Options)) (any, error) {
+	opts = s.with(opts)
+
+	approach, err := resolveApproach(opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(data, approach, opts...)
+}
+
+// ToBytesMany returns a byte slice of the provided data, as for the package function ToBytesMany
+func (s *Serialiser) ToBytesMany(data []any, opts ...func(*Options)) ([]byte, string, error) {
+	return ToBytesMany(data, s.with(opts)...)
+}
+
+// FromBytesMany deserialises the byte slice to an array of instances using the Approach of the Serialiser
+func (s *Serialiser) FromBytesMany(data []byte, opts ...func(*Options)) ([]any, error) {
+	opts = s.with(opts)
+
+	approach, err := resolveApproach(opts)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytesMany(data, approach, opts...)
+}
+
+// SizeOf returns the length of the serialised data, as for the package function SizeOf
+func (s *Serialiser) SizeOf(data any, opts ...func(*Options)) (int, error) {
+	return SizeOf(data, s.with(opts)...)
+}
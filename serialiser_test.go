@@ -0,0 +1,190 @@
+package serialise
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSerialiser(t *testing.T) {
+
+	var key = []byte("01234567890123456789012345678912")
+
+	s, err := NewSerialiser(
+		WithSerialisationApproach(NewMinDataApproachWithVersion(V3)),
+		WithAESGCMEncryption(key),
+		WithMaxSize(1000),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data := []int64{1, 2, 3, 4, 5}
+
+	b, name, err := s.ToBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "MD3" {
+		t.Fatalf("Expected MD3, got: %s", name)
+	}
+
+	// The data is encrypted, so cannot be deserialised with a different key
+	if _, err := FromBytes(b, NewMinDataApproachWithVersion(V3), WithAESGCMEncryption([]byte("98765432109876543210987654321098"))); err == nil {
+		t.Fatalf("Expected an error decrypting with a different key")
+	}
+
+	// Without decryption the ciphertext is unpacked directly.  An error is not guaranteed,
+	// as the random nonce may begin with the uncompressed flag and be followed by valid data,
+	// but the result can never be the original data.
+	if v, err := FromBytes(b, NewMinDataApproachWithVersion(V3)); err == nil && reflect.DeepEqual(v, data) {
+		t.Fatalf("Expected different data without decryption, got: %v", v)
+	}
+
+	v, err := s.FromBytes(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCompareSliceValue[int64](v, data, "[]int64", t)
+
+	buf, _, err := s.AppendBytes([]byte{42}, data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err = s.FromBytes(buf[1:])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testCompareSliceValue[int64](v, data, "[]int64", t)
+
+	items := []any{"a", int64(1)}
+	b, _, err = s.ToBytesMany(items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vs, err := s.FromBytesMany(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(vs) != len(items) || vs[0] != items[0] || vs[1] != items[1] {
+		t.Fatalf("Data mismatch: expected %v, got: %v", items, vs)
+	}
+
+	n, err := s.SizeOf(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected, _ := SizeOf(data, WithSerialisationApproach(NewMinDataApproachWithVersion(V3))); n != expected {
+		t.Fatalf("Size mismatch: expected %d, got: %d", expected, n)
+	}
+
+	// Options passed to methods are applied after the Serialiser's options
+	if _, _, err := s.ToBytes(strings.Repeat("a", 2000)); err != ErrMaxSizeExceeded {
+		t.Fatalf("Expected ErrMaxSizeExceeded, got: %v", err)
+	}
+	if _, _, err := s.ToBytes(strings.Repeat("a", 2000), WithMaxSize(0)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := NewSerialiser(WithCompressionLevel(42)); err != ErrInvalidCompressionLevel {
+		t.Fatalf("Expected ErrInvalidCompressionLevel, got: %v", err)
+	}
+	if _, err := NewSerialiser(WithSerialisationApproachName("testUnknown")); err != ErrUnknownApproach {
+		t.Fatalf("Expected ErrUnknownApproach, got: %v", err)
+	}
+}
+
+func TestSetDefaults(t *testing.T) {
+
+	defer SetDefaults()
+
+	if err := SetDefaults(WithCompressionLevel(42)); err != ErrInvalidCompressionLevel {
+		t.Fatalf("Expected ErrInvalidCompressionLevel, got: %v", err)
+	}
+	if Default() != defaultSerialisationApproach {
+		t.Fatalf("Expected defaults to be unchanged by invalid options")
+	}
+
+	if err := SetDefaults(WithSerialisationApproachName("MD2"), WithFlateThreshold(-1)); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if Default().Name() != "MD2" {
+		t.Fatalf("Expected MD2, got: %s", Default().Name())
+	}
+
+	data := strings.Repeat("a", 100)
+
+	b, name, err := ToBytes(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "MD2" || b[0] != uncompressedFlag {
+		t.Fatalf("Expected defaults to apply: got: %s, flag: %d", name, b[0])
+	}
+
+	// Options passed to each call are applied after the defaults
+	b, name, err = ToBytes(data, WithSerialisationApproach(NewMinDataApproachWithVersion(V1)), WithFlateThreshold(10))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "MD1" || b[0] != flateFlag {
+		t.Fatalf("Expected call options to apply: got: %s, flag: %d", name, b[0])
+	}
+
+	// Defaults can be cleared
+	if err := SetDefaults(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if Default() != defaultSerialisationApproach {
+		t.Fatalf("Expected defaults to be cleared")
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+
+	data := strings.Repeat("a", 1000)
+
+	for _, threshold := range []int{-1, 0} { // Uncompressed and compressed
+		b, _, err := ToBytes(data, WithFlateThreshold(threshold))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		size, err := SizeOf(data)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, _, err := ToBytes(data, WithFlateThreshold(threshold), WithMaxSize(size-1)); err != ErrMaxSizeExceeded {
+			t.Fatalf("(%d) Expected ErrMaxSizeExceeded, got: %v", threshold, err)
+		}
+		if _, _, err := ToBytes(data, WithFlateThreshold(threshold), WithMaxSize(size)); err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", threshold, err)
+		}
+
+		if _, err := FromBytes(b, defaultSerialisationApproach, WithMaxSize(size-1)); err != ErrMaxSizeExceeded {
+			t.Fatalf("(%d) Expected ErrMaxSizeExceeded, got: %v", threshold, err)
+		}
+		v, err := FromBytes(b, defaultSerialisationApproach, WithMaxSize(size))
+		if err != nil {
+			t.Fatalf("(%d) Unexpected error: %v", threshold, err)
+		}
+		if v.(string) != data {
+			t.Fatalf("(%d) Data mismatch after round trip", threshold)
+		}
+	}
+
+	items := []any{data, data}
+	b, _, err := ToBytesMany(items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, _, err := ToBytesMany(items, WithMaxSize(len(data))); err != ErrMaxSizeExceeded {
+		t.Fatalf("Expected ErrMaxSizeExceeded, got: %v", err)
+	}
+	if _, err := FromBytesMany(b, defaultSerialisationApproach, WithMaxSize(len(data))); err != ErrMaxSizeExceeded {
+		t.Fatalf("Expected ErrMaxSizeExceeded, got: %v", err)
+	}
+	if _, err := FromBytesMany(b, defaultSerialisationApproach, WithMaxSize(10*len(data))); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
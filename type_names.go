@@ -0,0 +1,169 @@
+package serialise
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	bitSetType   = reflect.TypeFor[BitSet]()
+	anyType      = reflect.TypeFor[any]()
+	bytesType    = reflect.TypeFor[[]byte]()
+)
+
+// errInvalidTypeName is raised if a type name is malformed; Approaches replace it with their own error
+var errInvalidTypeName = errors.New("type name is malformed")
+
+// builtinTypeNames holds the names of the types that are described without registration
+var builtinTypeNames = map[reflect.Type]string{
+	reflect.TypeFor[bool]():    "bool",
+	reflect.TypeFor[int8]():    "int8",
+	reflect.TypeFor[int16]():   "int16",
+	reflect.TypeFor[int32]():   "int32",
+	reflect.TypeFor[int64]():   "int64",
+	reflect.TypeFor[uint8]():   "uint8",
+	reflect.TypeFor[uint16]():  "uint16",
+	reflect.TypeFor[uint32]():  "uint32",
+	reflect.TypeFor[uint64]():  "uint64",
+	reflect.TypeFor[float32](): "float32",
+	reflect.TypeFor[float64](): "float64",
+	reflect.TypeFor[string]():  "string",
+	bytesType:                  "[]byte",
+	timeType:                   "time.Time",
+	durationType:               "time.Duration",
+	bitSetType:                 "BitSet",
+	anyType:                    "any",
+}
+
+// builtinNamedTypes is the reverse of builtinTypeNames
+var builtinNamedTypes = func() map[string]reflect.Type {
+	m := make(map[string]reflect.Type, len(builtinTypeNames)+1)
+	for t, name := range builtinTypeNames {
+		m[name] = t
+	}
+	m["byte"] = reflect.TypeFor[uint8]()
+	return m
+}()
+
+// goTypeName returns the description of the type using Go syntax, with the names of types
+// registered using Register or the TypeID of types registered using RegisterType (e.g. "#64"),
+// from which the type can be recreated by parseGoTypeName.  False is returned if the type
+// cannot be described.
+func goTypeName(t reflect.Type) (string, bool) {
+	if name, ok := builtinTypeNames[t]; ok {
+		return name, true
+	}
+
+	if c, ok := codecForType(t); ok {
+		return "#" + strconv.Itoa(int(c.id)), true
+	}
+
+	if name, ok := registeredName(t); ok {
+		if _, ok := marshalerTypeIDMD(t); ok {
+			return name, true
+		}
+		if _, ok := underlyingTypeMD(t); ok {
+			return name, true
+		}
+	}
+
+	// Unregistered named types are described as their underlying type
+	if u, ok := underlyingTypeMD(t); ok {
+		return goTypeName(u)
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structNameMD(t)
+	case reflect.Interface:
+		return registeredName(t)
+	case reflect.Pointer:
+		name, ok := goTypeName(t.Elem())
+		return "*" + name, ok
+	case reflect.Slice:
+		name, ok := goTypeName(t.Elem())
+		return "[]" + name, ok
+	case reflect.Map:
+		key, ok := goTypeName(t.Key())
+		if !ok {
+			return "", false
+		}
+		elem, ok := goTypeName(t.Elem())
+		return "map[" + key + "]" + elem, ok
+	}
+	return "", false
+}
+
+// parseGoTypeName returns the type described by a name created by goTypeName.
+// Registered names are matched first, as they may begin with "*" (e.g. "*url.URL").
+func parseGoTypeName(name string) (reflect.Type, error) {
+	if t, ok := builtinNamedTypes[name]; ok {
+		return t, nil
+	}
+
+	if t, ok := registeredType(name); ok {
+		if t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct {
+			if _, ok := marshalerTypeIDMD(t); !ok {
+				t = t.Elem() // Structs may be registered using a pointer to an instance
+			}
+		}
+		return t, nil
+	}
+
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, err := parseGoTypeName(name[1:])
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(elem), nil
+	case strings.HasPrefix(name, "[]"):
+		elem, err := parseGoTypeName(name[2:])
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case strings.HasPrefix(name, "map["):
+		// The key ends at the matching bracket, as the key may itself be a map
+		depth := 1
+		for i := 4; i < len(name); i++ {
+			switch name[i] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			if depth == 0 {
+				key, err := parseGoTypeName(name[4:i])
+				if err != nil {
+					return nil, err
+				}
+				elem, err := parseGoTypeName(name[i+1:])
+				if err != nil {
+					return nil, err
+				}
+				if !key.Comparable() {
+					return nil, errInvalidTypeName
+				}
+				return reflect.MapOf(key, elem), nil
+			}
+		}
+		return nil, errInvalidTypeName
+	case strings.HasPrefix(name, "#"):
+		id, err := strconv.ParseInt(name[1:], 10, 8)
+		if err != nil {
+			return nil, errInvalidTypeName
+		}
+		c, ok := codecForID(TypeID(id))
+		if !ok {
+			return nil, ErrUnknownTypeName
+		}
+		return c.t, nil
+	}
+	return nil, ErrUnknownTypeName
+}
@@ -0,0 +1,183 @@
+package serialise
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+type typeRegistry struct {
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+	lck    sync.RWMutex
+}
+
+var types = &typeRegistry{
+	byName: map[string]reflect.Type{},
+	byType: map[reflect.Type]string{},
+}
+
+// typeCodec holds the functions registered to serialise a user-defined type
+type typeCodec struct {
+	id     TypeID
+	t      reflect.Type
+	pack   func(v any) ([]byte, error)
+	unpack func(data []byte) (any, error)
+}
+
+type codecRegistry struct {
+	byID   map[TypeID]*typeCodec
+	byType map[reflect.Type]*typeCodec
+	lck    sync.RWMutex
+}
+
+var codecs = &codecRegistry{
+	byID:   map[TypeID]*typeCodec{},
+	byType: map[reflect.Type]*typeCodec{},
+}
+
+// ErrInvalidTypeRegistration raised if Register is called without a name or prototype
+var ErrInvalidTypeRegistration = errors.New("a name and a non-nil prototype must be provided to register a type")
+
+// ErrTypeNameAlreadyRegistered raised if the name passed to Register is already used by a different type
+var ErrTypeNameAlreadyRegistered = errors.New("type name is already registered to a different type")
+
+// ErrTypeAlreadyRegistered raised if the type passed to Register is already registered under a different name
+var ErrTypeAlreadyRegistered = errors.New("type is already registered under a different name")
+
+// Register records the concrete type of prototype against the specified name,
+// so that Approaches can write the name when serialising instances of the type
+// and reconstruct instances of the same type when deserialising.
+// The name must be stable across all processes that exchange serialised data.
+// Registering the same name and type more than once is permitted.
+//
+// Struct types may be registered using either an instance or a pointer to an instance,
+// and instances of both the struct and pointers to the struct can then be serialised.
+// Interface types, used as the element type of slices and maps, are registered
+// using a nil pointer to the interface, e.g. Register("Event", (*Event)(nil)).
+func Register(name string, prototype any) error {
+	if len(name) == 0 || prototype == nil {
+		return ErrInvalidTypeRegistration
+	}
+
+	t := reflect.TypeOf(prototype)
+	if t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Interface {
+		t = t.Elem()
+	}
+
+	types.lck.Lock()
+	defer types.lck.Unlock()
+
+	if existing, ok := types.byName[name]; ok {
+		if existing != t {
+			return ErrTypeNameAlreadyRegistered
+		}
+		return nil
+	}
+	if _, ok := types.byType[t]; ok {
+		return ErrTypeAlreadyRegistered
+	}
+
+	types.byName[name] = t
+	types.byType[t] = name
+	return nil
+}
+
+// registeredName returns the name under which the type was registered
+func registeredName(t reflect.Type) (string, bool) {
+	types.lck.RLock()
+	defer types.lck.RUnlock()
+
+	name, ok := types.byType[t]
+	return name, ok
+}
+
+// registeredType returns the type registered under the name
+func registeredType(name string) (reflect.Type, bool) {
+	types.lck.RLock()
+	defer types.lck.RUnlock()
+
+	t, ok := types.byName[name]
+	return t, ok
+}
+
+// ErrUserTypeIDOutOfRange raised if RegisterType is called with an id outside MinUserTypeID to MaxUserTypeID
+var ErrUserTypeIDOutOfRange = errors.New("user-defined TypeID must be within the range MinUserTypeID to MaxUserTypeID")
+
+// ErrTypeIDAlreadyRegistered raised if RegisterType is called with an id that is already in use
+var ErrTypeIDAlreadyRegistered = errors.New("TypeID is already registered to a different type")
+
+// RegisterType registers the functions used to serialise and deserialise instances of T,
+// which are identified within serialised data by id.  The id must lie within the range
+// MinUserTypeID to MaxUserTypeID and must be stable across all processes that exchange
+// serialised data.
+// Once registered, instances of T can be serialised by MinData directly, as the elements
+// of slices and maps, and as items passed to ToBytesMany.
+// The byte slice passed to unpack must not be retained after unpack returns.
+func RegisterType[T any](id TypeID, pack func(T) ([]byte, error), unpack func([]byte) (T, error)) error {
+	if id < MinUserTypeID || id > MaxUserTypeID {
+		return ErrUserTypeIDOutOfRange
+	}
+
+	t := reflect.TypeFor[T]()
+	if pack == nil || unpack == nil || t.Kind() == reflect.Interface {
+		return ErrInvalidTypeRegistration
+	}
+	if _, ok := builtinTypeIDs[t]; ok {
+		return ErrInvalidTypeRegistration
+	}
+
+	codecs.lck.Lock()
+	defer codecs.lck.Unlock()
+
+	if _, ok := codecs.byID[id]; ok {
+		return ErrTypeIDAlreadyRegistered
+	}
+	if _, ok := codecs.byType[t]; ok {
+		return ErrTypeAlreadyRegistered
+	}
+
+	c := &typeCodec{
+		id: id,
+		t:  t,
+		pack: func(v any) ([]byte, error) {
+			return pack(v.(T))
+		},
+		unpack: func(data []byte) (any, error) {
+			return unpack(data)
+		},
+	}
+
+	codecs.byID[id] = c
+	codecs.byType[t] = c
+	return nil
+}
+
+// codecForType returns the codec registered for the type
+func codecForType(t reflect.Type) (*typeCodec, bool) {
+	codecs.lck.RLock()
+	defer codecs.lck.RUnlock()
+
+	c, ok := codecs.byType[t]
+	return c, ok
+}
+
+// codecForID returns the codec registered for the TypeID
+func codecForID(id TypeID) (*typeCodec, bool) {
+	codecs.lck.RLock()
+	defer codecs.lck.RUnlock()
+
+	c, ok := codecs.byID[id]
+	return c, ok
+}
+
+// unregisterType removes the codec registered for the TypeID, so that tests can restore the registry
+func unregisterType(id TypeID) {
+	codecs.lck.Lock()
+	defer codecs.lck.Unlock()
+
+	if c, ok := codecs.byID[id]; ok {
+		delete(codecs.byType, c.t)
+		delete(codecs.byID, id)
+	}
+}
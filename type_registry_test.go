@@ -0,0 +1,474 @@
+package serialise
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testTextOnly struct {
+	A string
+	B string
+}
+
+func (t testTextOnly) MarshalText() ([]byte, error) {
+	return []byte(t.A + "|" + t.B), nil
+}
+
+func (t *testTextOnly) UnmarshalText(b []byte) error {
+	a, bb, _ := strings.Cut(string(b), "|")
+	t.A = a
+	t.B = bb
+	return nil
+}
+
+type testUnregisteredMarshaler struct{}
+
+func (t testUnregisteredMarshaler) MarshalBinary() ([]byte, error) {
+	return []byte{}, nil
+}
+
+func TestRegister(t *testing.T) {
+
+	type testRegisterA struct{}
+	type testRegisterB struct{}
+
+	if err := Register("", testRegisterA{}); err != ErrInvalidTypeRegistration {
+		t.Fatalf("Expected ErrInvalidTypeRegistration, got: %v", err)
+	}
+	if err := Register("testRegisterA", nil); err != ErrInvalidTypeRegistration {
+		t.Fatalf("Expected ErrInvalidTypeRegistration, got: %v", err)
+	}
+	if err := Register("testRegisterA", testRegisterA{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Register("testRegisterA", testRegisterA{}); err != nil {
+		t.Fatalf("Unexpected error on repeat registration: %v", err)
+	}
+	if err := Register("testRegisterA", testRegisterB{}); err != ErrTypeNameAlreadyRegistered {
+		t.Fatalf("Expected ErrTypeNameAlreadyRegistered, got: %v", err)
+	}
+	if err := Register("testRegisterA2", testRegisterA{}); err != ErrTypeAlreadyRegistered {
+		t.Fatalf("Expected ErrTypeAlreadyRegistered, got: %v", err)
+	}
+	if err := Register("testRegisterPtrA", &testRegisterA{}); err != nil {
+		t.Fatalf("Unexpected error registering pointer type: %v", err)
+	}
+}
+
+func TestMarshalerTypes(t *testing.T) {
+
+	if err := Register("netip.Addr", netip.Addr{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Register("*url.URL", &url.URL{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := Register("testTextOnly", testTextOnly{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	u, _ := url.Parse("https://example.com/path?q=1#frag")
+
+	tests := []any{
+		netip.MustParseAddr("192.168.1.1"),
+		netip.MustParseAddr("2001:db8::1"),
+		u,
+		testTextOnly{A: "Hello", B: "World"},
+	}
+
+	approach := NewMinDataApproach()
+
+	for _, test := range tests {
+
+		b, _, err := ToBytes(test, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error for %T: %v", test, err)
+		}
+
+		v, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error for %T: %v", test, err)
+		}
+
+		switch vv := v.(type) {
+		case netip.Addr:
+			if vv != test.(netip.Addr) {
+				t.Fatalf("Data mismatch: expected %v, got: %v", test, vv)
+			}
+		case *url.URL:
+			if vv.String() != u.String() {
+				t.Fatalf("Data mismatch: expected %v, got: %v", u, vv)
+			}
+		case testTextOnly:
+			if vv != test.(testTextOnly) {
+				t.Fatalf("Data mismatch: expected %v, got: %v", test, vv)
+			}
+		default:
+			t.Fatalf("Type mismatch: expected: %T, got: %T", test, v)
+		}
+	}
+
+	if approach.IsSerialisable(testUnregisteredMarshaler{}) {
+		t.Fatal("Expected unregistered marshaler to not be serialisable")
+	}
+
+	b, err := approach.Pack((*url.URL)(nil))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	v, err := approach.Unpack(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vv, ok := v.(*url.URL); !ok || vv != nil {
+		t.Fatalf("Expected typed nil *url.URL, got: %v (%T)", v, v)
+	}
+}
+
+type testPoint struct {
+	X int32
+	Y int32
+}
+
+func packTestPoint(p testPoint) ([]byte, error) {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(p.X))
+	return binary.LittleEndian.AppendUint32(b, uint32(p.Y)), nil
+}
+
+func unpackTestPoint(b []byte) (testPoint, error) {
+	if len(b) != 8 {
+		return testPoint{}, errors.New("invalid testPoint")
+	}
+	return testPoint{
+		X: int32(binary.LittleEndian.Uint32(b)),
+		Y: int32(binary.LittleEndian.Uint32(b[4:])),
+	}, nil
+}
+
+func TestRegisterType(t *testing.T) {
+
+	type testOther struct{}
+
+	if err := RegisterType(MinUserTypeID-1, packTestPoint, unpackTestPoint); err != ErrUserTypeIDOutOfRange {
+		t.Fatalf("Expected ErrUserTypeIDOutOfRange, got: %v", err)
+	}
+	if err := RegisterType[testPoint](MinUserTypeID, nil, unpackTestPoint); err != ErrInvalidTypeRegistration {
+		t.Fatalf("Expected ErrInvalidTypeRegistration, got: %v", err)
+	}
+	if err := RegisterType(MinUserTypeID, func(int8) ([]byte, error) { return nil, nil }, func([]byte) (int8, error) { return 0, nil }); err != ErrInvalidTypeRegistration {
+		t.Fatalf("Expected ErrInvalidTypeRegistration for builtin type, got: %v", err)
+	}
+	if err := RegisterType(MinUserTypeID, packTestPoint, unpackTestPoint); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	t.Cleanup(func() { unregisterType(MinUserTypeID) })
+	if err := RegisterType(MinUserTypeID, func(testOther) ([]byte, error) { return nil, nil }, func([]byte) (testOther, error) { return testOther{}, nil }); err != ErrTypeIDAlreadyRegistered {
+		t.Fatalf("Expected ErrTypeIDAlreadyRegistered, got: %v", err)
+	}
+	if err := RegisterType(MinUserTypeID+1, packTestPoint, unpackTestPoint); err != ErrTypeAlreadyRegistered {
+		t.Fatalf("Expected ErrTypeAlreadyRegistered, got: %v", err)
+	}
+
+	p := testPoint{X: -1, Y: 42}
+	approach := NewMinDataApproach()
+
+	roundTrip := func(v any) any {
+		b, _, err := ToBytes(v, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error packing %T: %v", v, err)
+		}
+		vv, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error unpacking %T: %v", v, err)
+		}
+		return vv
+	}
+
+	if v := roundTrip(p); v != p {
+		t.Fatalf("Data mismatch: expected %v, got: %v", p, v)
+	}
+
+	ps := []testPoint{p, {X: 1, Y: 2}, {}}
+	if v := roundTrip(ps); !slices.Equal(v.([]testPoint), ps) {
+		t.Fatalf("Data mismatch: expected %v, got: %v", ps, v)
+	}
+
+	pm := map[string]testPoint{"a": p, "b": {X: 3, Y: 4}}
+	if v := roundTrip(pm); !maps.Equal(v.(map[string]testPoint), pm) {
+		t.Fatalf("Data mismatch: expected %v, got: %v", pm, v)
+	}
+
+	pk := map[testPoint]string{p: "a"}
+	if v := roundTrip(pk); !maps.Equal(v.(map[testPoint]string), pk) {
+		t.Fatalf("Data mismatch: expected %v, got: %v", pk, v)
+	}
+
+	b, _, err := ToBytesMany([]any{p, int8(1), ps})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	vs, err := FromBytesMany(b, Default())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if vs[0] != p || vs[1] != int8(1) || !slices.Equal(vs[2].([]testPoint), ps) {
+		t.Fatalf("Data mismatch: got: %v", vs)
+	}
+}
+
+func TestSlicesAndMaps(t *testing.T) {
+
+	s := "Hello"
+	tm := time.Now().UTC()
+	approach := NewMinDataApproach()
+
+	tests := []any{
+		[][]int64{{1, 2}, {}, {3}},
+		[][][]string{{{"a"}, {"b", "c"}}},
+		[]time.Time{tm, tm.Add(time.Hour)},
+		[]*string{&s, nil},
+		map[string]int64{"a": 1, "b": 2},
+		map[int8][]string{1: {"a"}, 2: nil},
+		map[string]*string{"a": &s, "b": nil},
+		map[string]map[string]float64{"a": {"x": 1.5}},
+		[]map[uint16]bool{{1: true}, {}},
+	}
+
+	for _, test := range tests {
+		b, _, err := ToBytes(test, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error packing %T: %v", test, err)
+		}
+
+		v, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error unpacking %T: %v", test, err)
+		}
+
+		if fmt.Sprintf("%T", v) != fmt.Sprintf("%T", test) {
+			t.Fatalf("Type mismatch: expected: %T, got: %T", test, v)
+		}
+		if fmt.Sprint(derefAll(v)) != fmt.Sprint(derefAll(test)) {
+			t.Fatalf("Data mismatch: expected %v, got: %v", test, v)
+		}
+	}
+
+	// Map serialisation is deterministic
+	m := map[string]int64{}
+	for i := range 100 {
+		m[fmt.Sprint(i)] = int64(i)
+	}
+	b1, _ := approach.Pack(m)
+	b2, _ := approach.Pack(m)
+	if !bytes.Equal(b1, b2) {
+		t.Fatal("Expected map serialisation to be deterministic")
+	}
+
+	if approach.IsSerialisable([]chan int{}) {
+		t.Fatal("Expected slice of unsupported type to not be serialisable")
+	}
+	if approach.IsSerialisable(map[string]int{}) {
+		t.Fatal("Expected map of unsupported type to not be serialisable")
+	}
+}
+
+// derefAll replaces *string values so that output can be compared using fmt
+func derefAll(v any) any {
+	switch vv := v.(type) {
+	case []*string:
+		out := make([]string, len(vv))
+		for i, s := range vv {
+			if s != nil {
+				out[i] = *s
+			} else {
+				out[i] = "<nil>"
+			}
+		}
+		return out
+	case map[string]*string:
+		out := map[string]string{}
+		for k, s := range vv {
+			if s != nil {
+				out[k] = *s
+			} else {
+				out[k] = "<nil>"
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+type testStatus int8
+
+const (
+	testStatusActive testStatus = iota + 1
+	testStatusClosed
+)
+
+type testLabel string
+
+type testStatuses []testStatus
+
+type testUnregisteredCount uint32
+
+func TestNamedTypes(t *testing.T) {
+
+	for name, prototype := range map[string]any{
+		"testStatus":   testStatus(0),
+		"testLabel":    testLabel(""),
+		"testStatuses": testStatuses{},
+	} {
+		if err := Register(name, prototype); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", name, err)
+		}
+	}
+
+	approach := NewMinDataApproach()
+
+	tests := []struct {
+		V        any
+		Expected any
+	}{
+		{testStatusClosed, testStatusClosed},
+		{testLabel("GB"), testLabel("GB")},
+		{[]testStatus{testStatusActive, testStatusClosed}, []testStatus{testStatusActive, testStatusClosed}},
+		{testStatuses{testStatusClosed}, testStatuses{testStatusClosed}},
+		{map[testLabel]testStatus{"GB": testStatusActive}, map[testLabel]testStatus{"GB": testStatusActive}},
+		{testUnregisteredCount(7), uint32(7)},
+		{[]testUnregisteredCount{7, 8}, []uint32{7, 8}},
+	}
+
+	for _, test := range tests {
+		b, _, err := ToBytes(test.V, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error packing %T: %v", test.V, err)
+		}
+
+		v, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error unpacking %T: %v", test.V, err)
+		}
+
+		if fmt.Sprintf("%T", v) != fmt.Sprintf("%T", test.Expected) {
+			t.Fatalf("Type mismatch: expected: %T, got: %T", test.Expected, v)
+		}
+		if fmt.Sprint(v) != fmt.Sprint(test.Expected) {
+			t.Fatalf("Data mismatch: expected %v, got: %v", test.Expected, v)
+		}
+	}
+
+	if approach.IsSerialisable(struct{ A int8 }{}) {
+		t.Fatal("Expected unregistered struct to not be serialisable")
+	}
+}
+
+type testEvent interface {
+	Kind() string
+}
+
+type testClick struct {
+	X  int32
+	Y  int32
+	At time.Time
+}
+
+func (c testClick) Kind() string { return "click" }
+
+type testKeyPress struct {
+	Key  string
+	Mods []string
+}
+
+func (k *testKeyPress) Kind() string { return "key" }
+
+type testEnvelope struct {
+	ID      int64
+	Payload testEvent
+	Events  []testEvent
+	ByName  map[string]testEvent
+	Meta    any
+	Next    *testEnvelope
+	hidden  int
+}
+
+func TestInterfaceValues(t *testing.T) {
+
+	for name, prototype := range map[string]any{
+		"testEvent":    (*testEvent)(nil),
+		"testClick":    testClick{},
+		"testKeyPress": &testKeyPress{},
+		"testEnvelope": testEnvelope{},
+	} {
+		if err := Register(name, prototype); err != nil {
+			t.Fatalf("Unexpected error registering %s: %v", name, err)
+		}
+	}
+
+	click := testClick{X: 1, Y: -1, At: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)}
+	key := &testKeyPress{Key: "a", Mods: []string{"ctrl"}}
+
+	tests := []any{
+		click,
+		key,
+		(*testKeyPress)(nil),
+		[]testEvent{click, key, nil},
+		map[string]testEvent{"c": click, "k": key},
+		[]any{int8(1), "Hello", click},
+		testEnvelope{
+			ID:      42,
+			Payload: key,
+			Events:  []testEvent{click},
+			ByName:  map[string]testEvent{"k": key},
+			Meta:    []string{"x"},
+			Next:    &testEnvelope{ID: 43, Payload: click, Events: []testEvent{}, ByName: map[string]testEvent{}},
+		},
+	}
+
+	approach := NewMinDataApproach()
+
+	for _, test := range tests {
+		b, _, err := ToBytes(test, WithSerialisationApproach(approach))
+		if err != nil {
+			t.Fatalf("Unexpected error packing %T: %v", test, err)
+		}
+
+		v, err := FromBytes(b, approach)
+		if err != nil {
+			t.Fatalf("Unexpected error unpacking %T: %v", test, err)
+		}
+
+		if !reflect.DeepEqual(v, test) {
+			t.Fatalf("Data mismatch: expected %#v, got: %#v", test, v)
+		}
+	}
+
+	// Unexported fields are not serialised
+	b, _ := approach.Pack(testEnvelope{ID: 1, hidden: 2})
+	v, err := approach.Unpack(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e := v.(testEnvelope); e.ID != 1 || e.hidden != 0 {
+		t.Fatalf("Data mismatch: got: %#v", e)
+	}
+
+	type testUnregisteredEvent struct{ testClick }
+	if approach.IsSerialisable([]testEvent{testUnregisteredEvent{}}) {
+		t.Fatal("Expected unregistered concrete type to not be serialisable")
+	}
+	if approach.IsSerialisable([]fmt.Stringer{}) {
+		t.Fatal("Expected unregistered interface type to not be serialisable")
+	}
+}